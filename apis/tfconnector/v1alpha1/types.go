@@ -2,6 +2,7 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -28,24 +29,163 @@ type EnvVar struct {
 	// rtv1.env
 }
 
-// type BackendCredentials struct {
-// 	// Hostname of the Cloud Backend. (eg app.terraform.io)
-// 	Hostname *string `json:"hostname"`
-// 	// SecretRef reference to the secret containing the credentials.
-// 	SecretRef rtv1.SecretKeySelector `json:"secretRef"`
-// }
+// BackendCredentials are the API token of a remote host, e.g. a Terraform
+// Cloud compatible backend or a private module registry.
+type BackendCredentials struct {
+	// Hostname of the Cloud Backend. (eg app.terraform.io)
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9.-]+$`
+	Hostname string `json:"hostname"`
+	// A Secret key, in the namespace of the TFConnector, containing the
+	// API token.
+	SecretKeyRef corev1.SecretKeySelector `json:"secretKeyRef"`
+}
 
-type ProviderCredentials struct {
-	// // CredFile where to save credentials file.
-	// CredFilename string `json:"credFilename"`
+// A BackendConfig is a setting of the OpenTofu backend. Exactly one of
+// Value, ConfigMapKeyRef and SecretKeyRef should be set.
+type BackendConfig struct {
+	// Key of the backend setting, e.g. bucket.
+	Key string `json:"key"`
+
+	// Value of the backend setting. The {{ .Namespace }} and {{ .Name }}
+	// placeholders are replaced with the namespace and the name of the
+	// Workspace, e.g. key: "states/{{ .Namespace }}/{{ .Name }}.tfstate".
+	// +optional
+	Value *string `json:"value,omitempty"`
+
+	// A ConfigMap key, in the namespace of the TFConnector, containing the
+	// value of the backend setting.
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+
+	// A Secret key, in the namespace of the TFConnector, containing the
+	// value of the backend setting.
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// Backend configures the OpenTofu backend of the workspaces.
+type Backend struct {
+	// Type of the backend, e.g. s3. When set, it overrides the backend
+	// declared by the modules, if any.
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Config are the settings of the backend, passed to every tofu init
+	// as -backend-config.
+	// +optional
+	Config []BackendConfig `json:"config,omitempty"`
+}
+
+// A CredentialsFile is a Secret key written to a file for the providers,
+// e.g. a GCP service account key or AWS shared credentials.
+type CredentialsFile struct {
+	// Filename, relative to the module root, the credentials are written
+	// to, e.g. credentials.json. It is the path to set in the provider
+	// block of the module.
+	Filename string `json:"filename"`
+
+	// A Secret key, in the namespace of the TFConnector, containing the
+	// credentials.
+	SecretKeyRef corev1.SecretKeySelector `json:"secretKeyRef"`
+}
+
+// A PodTemplate customizes the pods of the runner Jobs.
+type PodTemplate struct {
+	// Labels added to the pods.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations added to the pods.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Resources of the runner container.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// NodeSelector of the pods.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations of the pods.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity of the pods.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// PriorityClassName of the pods.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// SecurityContext of the pods.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	SecurityContext *corev1.PodSecurityContext `json:"securityContext,omitempty"`
+
+	// ContainerSecurityContext is the security context of every container
+	// of the pods.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	ContainerSecurityContext *corev1.SecurityContext `json:"containerSecurityContext,omitempty"`
+}
+
+// A WorkingVolumeType is the kind of volume the runners work in.
+// +kubebuilder:validation:Enum=Ephemeral;EmptyDir
+type WorkingVolumeType string
+
+// Working volume types.
+const (
+	// WorkingVolumeEphemeral volumes are claims created for each runner.
+	WorkingVolumeEphemeral WorkingVolumeType = "Ephemeral"
+	// WorkingVolumeEmptyDir volumes are emptyDir volumes of the node of
+	// each runner.
+	WorkingVolumeEmptyDir WorkingVolumeType = "EmptyDir"
+)
+
+// A WorkingVolume configures the volume the runners work in, holding the
+// module and the providers installed by tofu init.
+type WorkingVolume struct {
+	// Type of the volume. Defaults to Ephemeral. EmptyDir volumes need no
+	// storage class, but they keep no saved plans, so applies plan again.
+	// +optional
+	Type WorkingVolumeType `json:"type,omitempty"`
+
+	// Size of the volume: the storage requested by Ephemeral volumes, or
+	// the size limit of EmptyDir ones. Defaults to 1Gi for Ephemeral
+	// volumes and to no limit for EmptyDir ones.
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
 
+	// StorageClassName of the claims of Ephemeral volumes and of the saved
+	// plans. Defaults to the default StorageClass of the cluster.
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+}
+
+type ProviderCredentials struct {
 	// EnvironmentVars to set for the provider.
-	EnvVars []corev1.EnvFromSource `json:"envVars"`
+	// +optional
+	EnvVars []corev1.EnvFromSource `json:"envVars,omitempty"`
+
+	// Files written next to the module before OpenTofu runs.
+	// +optional
+	Files []CredentialsFile `json:"files,omitempty"`
 }
 
 type TFConnectorSpec struct {
-	// // BackendCredentials required to authenticate. eg. Terraform Cloud
-	// BackendCredentials []BackendCredentials `json:"backendCredentials"`
+	// BackendCredentials required to authenticate. eg. Terraform Cloud
+	// Each token is set as the TF_TOKEN_<hostname> env var of the runner.
+	// +optional
+	BackendCredentials []BackendCredentials `json:"backendCredentials,omitempty"`
 
 	// EnvVars environment variables for OpenTofu cli.
 	// +optional
@@ -54,6 +194,10 @@ type TFConnectorSpec struct {
 	// +optional
 	ProvidersCredentials ProviderCredentials `json:"providersCredentials"`
 
+	// Backend configuration of the workspaces.
+	// +optional
+	Backend *Backend `json:"backend,omitempty"`
+
 	// GitCredentials required to authenticate. The name of the env var MUST be GIT_CREDENTIALS.
 	// eg. kubectl create secret generic git-creds --from-literal=GIT_CREDENTIALS=<TOKEN>
 	// +optional
@@ -62,8 +206,49 @@ type TFConnectorSpec struct {
 	// Configuration that should be injected into all workspaces that use
 	// this provider config, expressed as inline HCL. This can be used to
 	// automatically inject Terraform provider configuration blocks.
+	// The configuration is written to a krateo_connector_override.tf file
+	// next to the module, so its blocks are merged into those of the module
+	// as described in https://opentofu.org/docs/language/files/override/.
+	// +optional
+	Configuration *string `json:"configuration,omitempty"`
+
+	// ConfigurationRef is a ConfigMap key, in the namespace of the
+	// TFConnector, containing HCL configuration appended to Configuration.
+	// +optional
+	ConfigurationRef *corev1.ConfigMapKeySelector `json:"configurationRef,omitempty"`
+
+	// Version of OpenTofu run by the workspaces, i.e. the tag of the
+	// OpenTofu image, e.g. 1.10.6. Defaults to the --opentofu-version flag of
+	// the provider.
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Image is the reference of the OpenTofu image run by the workspaces,
+	// e.g. registry.example.com/opentofu/opentofu:1.10.6. It takes
+	// precedence over Version.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// GitImage is the image cloning the git modules of the workspaces.
+	// Defaults to the --git-image flag of the provider.
+	// +optional
+	GitImage string `json:"gitImage,omitempty"`
+
+	// ImagePullSecrets used to pull the images of the workspaces, in
+	// addition to those given by the --image-pull-secrets flag of the
+	// provider. The Secrets must exist in the namespace of each Workspace.
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// PodTemplate customizes the pods of the runner Jobs of the workspaces.
+	// +optional
+	PodTemplate *PodTemplate `json:"podTemplate,omitempty"`
+
+	// WorkingVolume configures the volume the runners of the workspaces
+	// work in.
 	// +optional
-	// Configuration *string `json:"configuration,omitempty"`
+	WorkingVolume *WorkingVolume `json:"workingVolume,omitempty"`
 }
 
 // +kubebuilder:object:root=true
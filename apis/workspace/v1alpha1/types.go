@@ -1,7 +1,9 @@
 package v1alpha1
 
 import (
+	connectorv1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/tfconnector/v1alpha1"
 	commonv1 "github.com/krateoplatformops/provider-runtime/apis/common/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -62,8 +64,10 @@ type VarFile struct {
 
 // A KeyReference references a key within a Secret or a ConfigMap.
 type KeyReference struct {
-	// Namespace of the referenced resource.
-	Namespace string `json:"namespace"`
+	// Namespace of the referenced resource. Resources of a Workspace can
+	// only be referenced from its namespace, which is the default.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
 
 	// Name of the referenced resource.
 	Name string `json:"name"`
@@ -72,6 +76,28 @@ type KeyReference struct {
 	Key string `json:"key"`
 }
 
+// AnnotationKeyExternalName is the annotation holding the name of the
+// OpenTofu workspace of a Workspace. When it is not set the default
+// workspace is used.
+const AnnotationKeyExternalName = "crossplane.io/external-name"
+
+// Annotations requesting a one-shot apply of a Workspace, limited to
+// (AnnotationKeyTarget) or replacing (AnnotationKeyReplace) the given comma
+// separated resource addresses, e.g. aws_instance.web. They are removed
+// once the apply is started.
+const (
+	AnnotationKeyTarget  = "opentofu.krateo.io/target"
+	AnnotationKeyReplace = "opentofu.krateo.io/replace"
+)
+
+// AnnotationKeyApprovePlan is the annotation approving the plan, whose ID
+// it holds, of a Workspace requiring approval.
+const AnnotationKeyApprovePlan = "opentofu.krateo.io/approve-plan"
+
+// AnnotationKeyOverrideGuardrails is the annotation allowing the plan,
+// whose ID it holds, of a Workspace to be applied despite its guardrails.
+const AnnotationKeyOverrideGuardrails = "opentofu.krateo.io/override-guardrails"
+
 // A ModuleSource represents the source of a OpenTofu module.
 // +kubebuilder:validation:Enum=Remote;Inline
 type ModuleSource string
@@ -82,56 +108,218 @@ const (
 	ModuleSourceInline ModuleSource = "Inline"
 )
 
+// A GitSource configures how a Remote module is cloned from a git repository.
+type GitSource struct {
+	// Ref is the branch or tag to check out. Defaults to the default branch
+	// of the repository.
+	// +optional
+	Ref string `json:"ref,omitempty"`
+
+	// Commit is the SHA of the commit to check out. It takes precedence over
+	// Ref, which is then only used to limit the branches that are fetched.
+	// +kubebuilder:validation:Pattern=`^[0-9a-fA-F]{7,64}$`
+	// +optional
+	Commit string `json:"commit,omitempty"`
+
+	// Depth creates a shallow clone with a history truncated to the specified
+	// number of commits. When Commit is set it must be a full SHA.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Depth *int32 `json:"depth,omitempty"`
+
+	// RecurseSubmodules initializes and clones the submodules of the
+	// repository.
+	// +optional
+	RecurseSubmodules bool `json:"recurseSubmodules,omitempty"`
+}
+
+// An ArchiveSource configures how a Remote module archive is downloaded.
+type ArchiveSource struct {
+	// Checksum the downloaded archive must match, in the form
+	// sha256:<hex digest>.
+	// +kubebuilder:validation:Pattern=`^sha256:[0-9a-f]{64}$`
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+}
+
 // WorkspaceParameters are the configurable fields of a Workspace.
 type WorkspaceParameters struct {
 	// The root module of this workspace; i.e. the module containing its main.tf
 	// file. When the workspace's source is 'Remote' (the default) this can be
 	// any address supported by tofu init -from-module, for example a git
-	// repository or an S3 bucket. When the workspace's source is 'Inline' the
-	// content of a simple main.tf file may be written inline.
+	// repository or an S3 bucket. Git repositories are cloned with git, .zip,
+	// .tar, .tar.gz and .tgz archives served over HTTP(S) are downloaded and
+	// extracted, s3::, gcs:: and oci:// addresses are fetched by OpenTofu.
+	// When the workspace's source is 'Inline' the content of a simple main.tf
+	// file may be written inline.
 	Module string `json:"module"`
 
-	// // Source of the root module of this workspace.
-	// Source ModuleSource `json:"source"`
+	// Source of the root module of this workspace.
+	// +kubebuilder:default=Remote
+	// +optional
+	Source ModuleSource `json:"source,omitempty"`
 
-	// // Entrypoint for `tofu init` within the module
-	// // +kubebuilder:default=""
-	// // +optional
-	// Entrypoint string `json:"entrypoint"`
+	// Git options used to clone a Remote module.
+	// +optional
+	Git *GitSource `json:"git,omitempty"`
 
-	// // Configuration variables.
-	// // +optional
-	// Vars []Var `json:"vars,omitempty"`
+	// Archive options used to download a Remote module archive.
+	// +optional
+	Archive *ArchiveSource `json:"archive,omitempty"`
 
-	// // Files of configuration variables. Explicitly declared vars take
-	// // precedence.
-	// // +optional
-	// VarFiles []VarFile `json:"varFiles,omitempty"`
+	// Entrypoint for `tofu init` within the module; i.e. the path of the
+	// root module relative to the root of the cloned repository. It must not
+	// point outside of the repository.
+	// +kubebuilder:default=""
+	// +optional
+	Entrypoint string `json:"entrypoint,omitempty"`
 
-	// // Arguments to be included in the tofu init CLI command
-	// InitArgs []string `json:"initArgs,omitempty"`
+	// Configuration variables.
+	// +optional
+	Vars []Var `json:"vars,omitempty"`
 
-	// // Arguments to be included in the tofu plan CLI command
-	// PlanArgs []string `json:"planArgs,omitempty"`
+	// Files of configuration variables. Explicitly declared vars take
+	// precedence.
+	// +optional
+	VarFiles []VarFile `json:"varFiles,omitempty"`
 
-	// // Arguments to be included in the tofu apply CLI command
-	// ApplyArgs []string `json:"applyArgs,omitempty"`
+	// Env are env vars of the runner, set on top of those of the TFConnector,
+	// e.g. AWS_REGION or TF_VAR_environment. They take precedence over any
+	// other env var of the runner.
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
 
-	// // Arguments to be included in the tofu destroy CLI command
-	// DestroyArgs []string `json:"destroyArgs,omitempty"`
+	// EnvFrom are sources of env vars of the runner, in the namespace of the
+	// Workspace. They take precedence over the env vars of the TFConnector
+	// sources.
+	// +optional
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+
+	// Arguments to be included in the tofu init CLI command
+	// +optional
+	InitArgs []string `json:"initArgs,omitempty"`
+
+	// Arguments to be included in the tofu plan CLI command
+	// +optional
+	PlanArgs []string `json:"planArgs,omitempty"`
+
+	// Arguments to be included in the tofu apply CLI command
+	// +optional
+	ApplyArgs []string `json:"applyArgs,omitempty"`
+
+	// Arguments to be included in the tofu destroy CLI command
+	// +optional
+	DestroyArgs []string `json:"destroyArgs,omitempty"`
+
+	// RequireApproval holds the changes found by a plan until they are
+	// approved by setting the opentofu.krateo.io/approve-plan annotation
+	// to the ID of the plan.
+	// +optional
+	RequireApproval bool `json:"requireApproval,omitempty"`
+
+	// Guardrails block applying plans that may be harmful.
+	// +optional
+	Guardrails *Guardrails `json:"guardrails,omitempty"`
+
+	// Version of OpenTofu run for this workspace, i.e. the tag of the
+	// OpenTofu image, e.g. 1.10.6. Together with Image, it overrides the
+	// version and the image of the TFConnector.
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Image is the reference of the OpenTofu image run for this workspace,
+	// e.g. registry.example.com/opentofu/opentofu:1.10.6. It takes
+	// precedence over Version.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// PodTemplate is merged into the pod template of the TFConnector:
+	// labels, annotations, node selector and resources are merged,
+	// tolerations are appended and any other field is replaced.
+	// +optional
+	PodTemplate *connectorv1alpha1.PodTemplate `json:"podTemplate,omitempty"`
+
+	// WorkingVolume overrides the fields of the working volume of the
+	// TFConnector it sets.
+	// +optional
+	WorkingVolume *connectorv1alpha1.WorkingVolume `json:"workingVolume,omitempty"`
 
 	// // Cloud - set this flag to true if running on terraform cloud
 	// Cloud bool `json:"cloud,omitempty"`
 }
 
+// Guardrails block applying the plans of a Workspace that delete or
+// replace protected resources, or that change too many resources, unless
+// they are overridden with the opentofu.krateo.io/override-guardrails
+// annotation set to the ID of the plan.
+type Guardrails struct {
+	// ProtectedResources are patterns, in the syntax of Go path.Match, of
+	// the addresses or types of resources that must not be deleted or
+	// replaced, e.g. aws_db_instance or module.db.*.
+	// +optional
+	ProtectedResources []string `json:"protectedResources,omitempty"`
+
+	// MaxChanges is the number of resources a plan may add, change and
+	// destroy in total.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxChanges *int32 `json:"maxChanges,omitempty"`
+}
+
+// PlannedChanges counts the resources a plan changes. A replaced resource
+// counts both as added and as destroyed.
+type PlannedChanges struct {
+	// Add is the number of resources to create.
+	Add int32 `json:"add"`
+	// Change is the number of resources to update in-place.
+	Change int32 `json:"change"`
+	// Destroy is the number of resources to destroy.
+	Destroy int32 `json:"destroy"`
+}
+
 // WorkspaceObservation are the observable fields of a Workspace.
-// type WorkspaceObservation struct {
-// 	Outputs map[string]string `json:"outputs,omitempty"`
-// }
+type WorkspaceObservation struct {
+	// Outputs of the root module that are not sensitive. Values of types
+	// other than string are JSON encoded. All outputs, sensitive ones
+	// included, are written to the connection secret, if any.
+	// +optional
+	Outputs map[string]string `json:"outputs,omitempty"`
+
+	// Commit is the SHA of the module commit last applied.
+	// +optional
+	Commit string `json:"commit,omitempty"`
+
+	// DriftDetected is true when the last plan found changes that are not
+	// applied yet.
+	// +optional
+	DriftDetected bool `json:"driftDetected,omitempty"`
+
+	// PlannedChanges are the changes found by the last plan that are not
+	// applied yet.
+	// +optional
+	PlannedChanges *PlannedChanges `json:"plannedChanges,omitempty"`
+
+	// PendingPlanID is the ID of the plan waiting for approval, if any. The
+	// plan is stored in the <name>-opentofu-plan ConfigMap.
+	// +optional
+	PendingPlanID string `json:"pendingPlanID,omitempty"`
+
+	// OpenTofuVersion is the version of OpenTofu that ran the last plan or
+	// apply.
+	// +optional
+	OpenTofuVersion string `json:"opentofuVersion,omitempty"`
+}
 
 // A WorkspaceSpec defines the desired state of a Workspace.
 type WorkspaceSpec struct {
 	commonv1.ManagedSpec `json:",inline"`
+	// WriteConnectionSecretToRef specifies the name and namespace of a Secret
+	// to which the outputs of the root module are written after each apply.
+	// The Secret must be in the namespace of the Workspace; an existing
+	// Secret is only updated when it is owned by the Workspace.
+	// +optional
+	WriteConnectionSecretToRef *commonv1.Reference `json:"writeConnectionSecretToRef,omitempty"`
 	// ConnectorConfigRef: configuration spec for
 	// +immutable
 	TFConnectorRef *commonv1.Reference `json:"tfConnectorRef,omitempty"`
@@ -143,7 +331,8 @@ type WorkspaceSpec struct {
 // A WorkspaceStatus represents the observed state of a Workspace.
 type WorkspaceStatus struct {
 	commonv1.ManagedStatus `json:",inline"`
-	Error                  *string `json:"error,omitempty"`
+	AtProvider             WorkspaceObservation `json:"atProvider,omitempty"`
+	Error                  *string              `json:"error,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/alecthomas/kingpin.v2"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -16,6 +17,7 @@ import (
 	"github.com/krateoplatformops/provider-runtime/pkg/logging"
 	"github.com/krateoplatformops/provider-runtime/pkg/ratelimiter"
 
+	tofu "github.com/krateoplatformops/opentofu-provider/internal/clients/opentofu"
 	opentofu "github.com/krateoplatformops/opentofu-provider/internal/controllers"
 	"github.com/krateoplatformops/provider-runtime/pkg/controller"
 
@@ -54,6 +56,26 @@ func main() {
 				Default("false").
 				OverrideDefaultFromEnvar(fmt.Sprintf("%s_LEADER_ELECTION", envVarPrefix)).
 				Bool()
+		opentofuVersion = app.Flag("opentofu-version", "Version of OpenTofu run by workspaces that do not choose one.").
+				Default(tofu.DefaultVersion).
+				OverrideDefaultFromEnvar(fmt.Sprintf("%s_OPENTOFU_VERSION", envVarPrefix)).
+				String()
+		opentofuRepository = app.Flag("opentofu-repository", "Repository of the OpenTofu image run by workspaces.").
+					Default(tofu.DefaultRepository).
+					OverrideDefaultFromEnvar(fmt.Sprintf("%s_OPENTOFU_REPOSITORY", envVarPrefix)).
+					String()
+		gitImage = app.Flag("git-image", "Image cloning the git modules of workspaces.").
+				Default(tofu.DefaultGitImage).
+				OverrideDefaultFromEnvar(fmt.Sprintf("%s_GIT_IMAGE", envVarPrefix)).
+				String()
+		reporterImage = app.Flag("reporter-image", "Image storing the reports of the runner jobs, i.e. the image of the provider.").
+				Default(tofu.DefaultReporterImage()).
+				OverrideDefaultFromEnvar(fmt.Sprintf("%s_REPORTER_IMAGE", envVarPrefix)).
+				String()
+		imagePullSecrets = app.Flag("image-pull-secrets", "Comma separated names of the Secrets, in the namespace of each workspace, used to pull the runner images.").
+					Default("").
+					OverrideDefaultFromEnvar(fmt.Sprintf("%s_IMAGE_PULL_SECRETS", envVarPrefix)).
+					String()
 	)
 	kingpin.MustParse(app.Parse(os.Args[1:]))
 
@@ -90,7 +112,26 @@ func main() {
 		GlobalRateLimiter:       ratelimiter.NewGlobal(*maxReconcileRate),
 	}
 
+	defaults := tofu.RunnerDefaults{
+		Repository:       *opentofuRepository,
+		Version:          *opentofuVersion,
+		GitImage:         *gitImage,
+		ReporterImage:    *reporterImage,
+		ImagePullSecrets: splitNames(*imagePullSecrets),
+	}
+
 	kingpin.FatalIfError(apis.AddToScheme(mgr.GetScheme()), "Cannot add APIs to scheme")
-	kingpin.FatalIfError(opentofu.Setup(mgr, o), "Cannot setup controllers")
+	kingpin.FatalIfError(opentofu.Setup(mgr, o, defaults), "Cannot setup controllers")
 	kingpin.FatalIfError(mgr.Start(ctrl.SetupSignalHandler()), "Cannot start controller manager")
 }
+
+// splitNames returns the names of a comma separated list.
+func splitNames(list string) []string {
+	names := []string{}
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
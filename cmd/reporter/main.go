@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tofu "github.com/krateoplatformops/opentofu-provider/internal/clients/opentofu"
+)
+
+// The reporter runs as the last container of the runner Jobs: it stores
+// the files written by OpenTofu into the report Secret of the Job.
+func main() {
+	var (
+		app = kingpin.New(filepath.Base(os.Args[0]), "Krateo opentofu provider runner reporter.")
+
+		secret = app.Flag("secret", "Name of the report Secret.").
+			Required().
+			String()
+		namespace = app.Flag("namespace", "Namespace of the report Secret.").
+				Required().
+				String()
+		dir = app.Flag("dir", "Directory of the files written by OpenTofu.").
+			Required().
+			String()
+		timeout = app.Flag("timeout", "Timeout of the report.").
+			Default("1m").
+			Duration()
+	)
+	kingpin.MustParse(app.Parse(os.Args[1:]))
+
+	cfg, err := ctrl.GetConfig()
+	kingpin.FatalIfError(err, "Cannot get API server rest config")
+
+	kube, err := client.New(cfg, client.Options{})
+	kingpin.FatalIfError(err, "Cannot create client")
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	kingpin.FatalIfError(tofu.WriteReport(ctx, kube, *secret, *namespace, *dir), "Cannot write report")
+}
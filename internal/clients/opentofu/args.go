@@ -0,0 +1,65 @@
+package opentofu
+
+import (
+	"fmt"
+	"strings"
+)
+
+// deniedArgs are the flags, by OpenTofu command, that would break the
+// commands run by the controller if set by users.
+var deniedArgs = map[string][]string{
+	"init":    {"chdir", "help", "input", "from-module"},
+	"plan":    {"chdir", "help", "input", "json", "out", "destroy", "detailed-exitcode"},
+	"apply":   {"chdir", "help", "input", "json", "auto-approve", "destroy"},
+	"destroy": {"chdir", "help", "input", "json", "auto-approve"},
+}
+
+// ValidateArgs returns an error when args contain a flag the controller
+// relies on for the given OpenTofu command.
+func ValidateArgs(command string, args []string) error {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+
+		name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		for _, denied := range deniedArgs[command] {
+			if name == denied {
+				return fmt.Errorf("argument %q is not allowed for tofu %s", arg, command)
+			}
+		}
+	}
+
+	return nil
+}
+
+// savedPlanApplyArgs are the flags of tofu apply that are accepted along
+// with a saved plan; any other flag changes what is planned.
+var savedPlanApplyArgs = []string{"compact-warnings", "concise", "lock", "lock-timeout", "parallelism", "show-sensitive", "state", "state-out", "backup"}
+
+// splitApplyArgs splits the arguments of tofu apply into those that change
+// what is planned, e.g. -var and -target, and those that are accepted along
+// with a saved plan. A value passed as a separate argument goes with its
+// flag.
+func splitApplyArgs(args []string) (planning, applying []string) {
+	toApply := false
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+			toApply = false
+			for _, a := range savedPlanApplyArgs {
+				if name == a {
+					toApply = true
+					break
+				}
+			}
+		}
+
+		if toApply {
+			applying = append(applying, arg)
+		} else {
+			planning = append(planning, arg)
+		}
+	}
+	return planning, applying
+}
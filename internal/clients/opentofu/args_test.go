@@ -0,0 +1,74 @@
+package opentofu
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		args    []string
+		wantErr bool
+	}{
+		{name: "no args", command: "plan"},
+		{name: "allowed flags", command: "plan", args: []string{"-parallelism=20", "-refresh=false"}},
+		{name: "values are not flags", command: "plan", args: []string{"-var-file", "out"}},
+		{name: "denied flag", command: "plan", args: []string{"-out=plan.tfplan"}, wantErr: true},
+		{name: "denied flag with double dash", command: "apply", args: []string{"--auto-approve"}, wantErr: true},
+		{name: "denied flag without value", command: "plan", args: []string{"-detailed-exitcode"}, wantErr: true},
+		{name: "denied for every command", command: "destroy", args: []string{"-chdir=modules"}, wantErr: true},
+		{name: "denied for another command only", command: "init", args: []string{"-json"}},
+		{name: "prefix of a denied flag", command: "plan", args: []string{"-outputs"}},
+		{name: "unknown command", command: "show", args: []string{"-json"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateArgs(tt.command, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateArgs(%q, %q) error = %v, wantErr %v", tt.command, tt.args, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSplitApplyArgs(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		wantPlanning []string
+		wantApplying []string
+	}{
+		{name: "none"},
+		{
+			name:         "planning flags",
+			args:         []string{"-var=a=1", "-target=aws_instance.web", "-refresh=false"},
+			wantPlanning: []string{"-var=a=1", "-target=aws_instance.web", "-refresh=false"},
+		},
+		{
+			name:         "apply flags",
+			args:         []string{"-parallelism=5", "-lock=false", "--compact-warnings"},
+			wantApplying: []string{"-parallelism=5", "-lock=false", "--compact-warnings"},
+		},
+		{
+			name:         "values follow their flag",
+			args:         []string{"-lock-timeout", "30s", "-var", "a=1", "-parallelism=2"},
+			wantPlanning: []string{"-var", "a=1"},
+			wantApplying: []string{"-lock-timeout", "30s", "-parallelism=2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			planning, applying := splitApplyArgs(tt.args)
+			if !reflect.DeepEqual(planning, tt.wantPlanning) {
+				t.Errorf("splitApplyArgs() planning = %q, want %q", planning, tt.wantPlanning)
+			}
+			if !reflect.DeepEqual(applying, tt.wantApplying) {
+				t.Errorf("splitApplyArgs() applying = %q, want %q", applying, tt.wantApplying)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package opentofu
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	connectorv1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/tfconnector/v1alpha1"
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+	"github.com/krateoplatformops/opentofu-provider/internal/controllers/resolvers"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	backendOverrideFile = "krateo_backend_override.tf"
+	backendConfigFile   = "backend.tfbackend"
+	defaultBackendFile  = "krateo_default_backend.tf"
+)
+
+// declaresBackend matches the lines of a module declaring a backend or
+// cloud block, one-line terraform blocks included.
+const declaresBackend = `^[[:space:]]*(terraform[[:space:]]*\{.*)?(backend[[:space:]]+"|cloud[[:space:]]*\{)`
+
+// declaresJSONBackend matches a backend or cloud block of a module written
+// in the JSON syntax, once whitespace is removed. Backends are objects
+// keyed by their type, unlike the backend attributes of some resources.
+const declaresJSONBackend = `"backend":\[?\{"[^"]+":[[{]|"cloud":[[{]`
+
+// declaresBackendCMD succeeds when a file of the module declares a backend
+// or cloud block.
+var declaresBackendCMD = fmt.Sprintf(`{ grep -qsE '%s' *.tf || cat *.tf.json 2>/dev/null | tr -d '[:space:]' | grep -qE '%s'; }`, declaresBackend, declaresJSONBackend)
+
+var hclIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// backendTemplateData are the values backend settings are templated with.
+type backendTemplateData struct {
+	Namespace string
+	Name      string
+}
+
+// resolveBackendArgs renders the backend of the TFConnector into runner
+// files and returns the -backend-config flags of tofu init.
+func resolveBackendArgs(ctx context.Context, kube client.Client, cfg *connectorv1alpha1.TFConnector, cr workspacev1alpha1.Workspace, files *runnerFiles) ([]string, error) {
+	backend := cfg.Spec.Backend
+	if backend == nil {
+		return nil, nil
+	}
+
+	if backend.Type != "" {
+		if !hclIdentifier.MatchString(backend.Type) {
+			return nil, fmt.Errorf("invalid backend type %q", backend.Type)
+		}
+		override := fmt.Sprintf("terraform {\n  backend %q {}\n}\n", backend.Type)
+		files.AddToModule(backendOverrideFile, []byte(override), backendOverrideFile)
+	}
+
+	if len(backend.Config) == 0 {
+		return nil, nil
+	}
+
+	data := backendTemplateData{
+		Namespace: cr.GetNamespace(),
+		Name:      cr.GetName(),
+	}
+
+	var sb strings.Builder
+	for _, c := range backend.Config {
+		if !hclIdentifier.MatchString(c.Key) {
+			return nil, fmt.Errorf("invalid backend setting %q", c.Key)
+		}
+
+		value, err := resolveBackendValue(ctx, kube, cfg.GetNamespace(), c, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve backend setting %s: %w", c.Key, err)
+		}
+
+		fmt.Fprintf(&sb, "%s = %s\n", c.Key, hclString(value))
+	}
+
+	return []string{fmt.Sprintf("-backend-config=%s", files.Add(backendConfigFile, []byte(sb.String())))}, nil
+}
+
+// defaultBackendCMD returns the command that, when no file of the module
+// declares a backend, configures the kubernetes backend to keep the state
+// in a Secret in the namespace of the Workspace. The runner volume does not
+// outlive the Job, so the state would otherwise be lost after each run.
+func defaultBackendCMD(cr workspacev1alpha1.Workspace, files *runnerFiles) string {
+	backend := fmt.Sprintf(`terraform {
+  backend "kubernetes" {
+    namespace         = %s
+    secret_suffix     = %s
+    in_cluster_config = true
+  }
+}
+`, hclString(cr.GetNamespace()), hclString(stateSecretSuffix(cr.GetName())))
+	src := files.Add(defaultBackendFile, []byte(backend))
+
+	return fmt.Sprintf("{ %s || { echo 'No backend declared by the module, using the kubernetes backend.' && cp %s %s; }; }", declaresBackendCMD, src, backendOverrideFile)
+}
+
+// stateSecretSuffix returns the secret_suffix of the kubernetes backend for
+// a Workspace. The backend also uses it as label value, hence it is kept
+// within 63 characters.
+func stateSecretSuffix(name string) string {
+	if len(name) <= 63 {
+		return name
+	}
+	sum := sha256.Sum256([]byte(name))
+	return name[:54] + "-" + hex.EncodeToString(sum[:])[:8]
+}
+
+func resolveBackendValue(ctx context.Context, kube client.Client, namespace string, c connectorv1alpha1.BackendConfig, data backendTemplateData) (string, error) {
+	switch {
+	case c.Value != nil:
+		tmpl, err := template.New(c.Key).Option("missingkey=error").Parse(*c.Value)
+		if err != nil {
+			return "", err
+		}
+
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, data); err != nil {
+			return "", err
+		}
+		return sb.String(), nil
+	case c.ConfigMapKeyRef != nil:
+		return resolvers.GetConfigMapValue(ctx, kube, &workspacev1alpha1.KeyReference{
+			Namespace: namespace,
+			Name:      c.ConfigMapKeyRef.Name,
+			Key:       c.ConfigMapKeyRef.Key,
+		})
+	case c.SecretKeyRef != nil:
+		value, err := resolvers.GetSecretValue(ctx, kube, &workspacev1alpha1.KeyReference{
+			Namespace: namespace,
+			Name:      c.SecretKeyRef.Name,
+			Key:       c.SecretKeyRef.Key,
+		})
+		return string(value), err
+	default:
+		return "", fmt.Errorf("one of value, configMapKeyRef and secretKeyRef must be set")
+	}
+}
+
+// hclString returns s as a quoted HCL string. JSON escapes are valid HCL
+// ones; template sequences are escaped so that s is taken literally.
+func hclString(s string) string {
+	b, _ := json.Marshal(s)
+	return strings.NewReplacer("${", "$${", "%{", "%%{").Replace(string(b))
+}
@@ -0,0 +1,87 @@
+package opentofu
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestDeclaresBackendCMD(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  bool
+	}{
+		{
+			name: "no files",
+			want: false,
+		},
+		{
+			name:  "no backend",
+			files: map[string]string{"main.tf": "terraform {\n  required_version = \">= 1.6\"\n}\n"},
+			want:  false,
+		},
+		{
+			name:  "backend block",
+			files: map[string]string{"main.tf": "terraform {\n  backend \"s3\" {\n    bucket = \"state\"\n  }\n}\n"},
+			want:  true,
+		},
+		{
+			name:  "cloud block",
+			files: map[string]string{"main.tf": "terraform {\n  cloud {\n    organization = \"krateo\"\n  }\n}\n"},
+			want:  true,
+		},
+		{
+			name:  "one-line backend block",
+			files: map[string]string{"backend.tf": "terraform { backend \"s3\" {} }\n"},
+			want:  true,
+		},
+		{
+			name:  "one-line cloud block",
+			files: map[string]string{"backend.tf": "terraform { cloud {} }\n"},
+			want:  true,
+		},
+		{
+			name:  "commented out backend block",
+			files: map[string]string{"main.tf": "terraform {\n  # backend \"s3\" {}\n}\n"},
+			want:  false,
+		},
+		{
+			name:  "JSON backend",
+			files: map[string]string{"main.tf.json": "{\n  \"terraform\": {\n    \"backend\": {\n      \"s3\": {\n        \"bucket\": \"state\"\n      }\n    }\n  }\n}\n"},
+			want:  true,
+		},
+		{
+			name:  "JSON cloud block",
+			files: map[string]string{"main.tf.json": `{"terraform": [{"cloud": [{"organization": "krateo"}]}]}`},
+			want:  true,
+		},
+		{
+			name:  "JSON backend attribute of a resource",
+			files: map[string]string{"main.tf.json": `{"resource": {"google_compute_backend_service": {"web": {"backend": {"group": "g"}}}}}`},
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cmd := exec.Command("sh", "-c", declaresBackendCMD)
+			cmd.Dir = dir
+			if got := cmd.Run() == nil; got != tt.want {
+				t.Errorf("%s = %v, want %v", declaresBackendCMD, got, tt.want)
+			}
+		})
+	}
+}
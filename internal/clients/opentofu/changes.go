@@ -0,0 +1,217 @@
+package opentofu
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Placeholders of the attribute values that are not shown.
+var (
+	sensitiveValue = json.RawMessage(`"(sensitive value)"`)
+	unknownValue   = json.RawMessage(`"(known after apply)"`)
+)
+
+// A ResourceChange is the condensed description of the change of a
+// resource in a plan.
+type ResourceChange struct {
+	// Address of the resource, e.g. aws_instance.web.
+	Address string `json:"address"`
+	// Type of the resource, e.g. aws_instance.
+	Type string `json:"type"`
+	// Action is one of create, read, update, delete and replace.
+	Action string `json:"action"`
+	// Provider of the resource, e.g. registry.opentofu.org/hashicorp/aws.
+	Provider string `json:"provider"`
+	// ActionReason explains the action, e.g. replace_because_tainted.
+	ActionReason string `json:"actionReason,omitempty"`
+	// ReplacePaths are the attributes forcing the replacement.
+	ReplacePaths []string `json:"replacePaths,omitempty"`
+	// Attributes are the top-level attributes that change. Sensitive
+	// values are redacted.
+	Attributes map[string]AttributeChange `json:"attributes,omitempty"`
+}
+
+// An AttributeChange holds the values of an attribute before and after
+// the change.
+type AttributeChange struct {
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// ParseResourceChanges returns the changes of the saved plan reported by
+// an InitPlan job. Resources that do not change are omitted.
+func ParseResourceChanges(data []byte) ([]ResourceChange, error) {
+	plan, err := parsePlanJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []ResourceChange{}
+	for _, rc := range plan.ResourceChanges {
+		action := changeAction(rc.Change.Actions)
+		if action == "" {
+			continue
+		}
+
+		change := ResourceChange{
+			Address:      rc.Address,
+			Type:         rc.Type,
+			Action:       action,
+			Provider:     rc.ProviderName,
+			ActionReason: rc.ActionReason,
+		}
+		for _, p := range rc.Change.ReplacePaths {
+			change.ReplacePaths = append(change.ReplacePaths, formatPath(p))
+		}
+		if action != "delete" {
+			change.Attributes = changedAttributes(rc.Change.Before, rc.Change.After,
+				rc.Change.AfterUnknown, rc.Change.BeforeSensitive, rc.Change.AfterSensitive)
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// changeAction returns the action of a list of plan actions, or an empty
+// string for no-op.
+func changeAction(actions []string) string {
+	switch {
+	case len(actions) == 2:
+		return "replace"
+	case len(actions) == 1 && actions[0] != "no-op":
+		return actions[0]
+	default:
+		return ""
+	}
+}
+
+// changedAttributes returns the top-level attributes whose value changes,
+// or is unknown until apply. Attributes holding any sensitive value are
+// redacted as a whole, and all of them are when the whole value is.
+func changedAttributes(before, after, afterUnknown, beforeSensitive, afterSensitive json.RawMessage) map[string]AttributeChange {
+	b, a := objectFields(before), objectFields(after)
+	unknown := objectFields(afterUnknown)
+	bs, as := objectFields(beforeSensitive), objectFields(afterSensitive)
+	allBeforeSensitive, allAfterSensitive := isTrue(beforeSensitive), isTrue(afterSensitive)
+
+	names := map[string]bool{}
+	for name := range b {
+		names[name] = true
+	}
+	for name := range a {
+		names[name] = true
+	}
+	for name := range unknown {
+		names[name] = true
+	}
+
+	attrs := map[string]AttributeChange{}
+	for name := range names {
+		isUnknown := anyTrue(unknown[name])
+		if !isUnknown && jsonEqual(b[name], a[name]) {
+			continue
+		}
+
+		change := AttributeChange{Before: b[name], After: a[name]}
+		if (allBeforeSensitive || anyTrue(bs[name])) && change.Before != nil {
+			change.Before = sensitiveValue
+		}
+		switch {
+		case isUnknown:
+			change.After = unknownValue
+		case (allAfterSensitive || anyTrue(as[name])) && change.After != nil:
+			change.After = sensitiveValue
+		}
+		attrs[name] = change
+	}
+
+	if len(attrs) == 0 {
+		return nil
+	}
+	return attrs
+}
+
+// objectFields returns the fields of a JSON object; it is empty for any
+// other value.
+func objectFields(raw json.RawMessage) map[string]json.RawMessage {
+	fields := map[string]json.RawMessage{}
+	_ = json.Unmarshal(raw, &fields)
+	for name, value := range fields {
+		if bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+			delete(fields, name)
+		}
+	}
+	return fields
+}
+
+// isTrue returns whether raw is true, as in the sensitivity markers of a
+// plan for values that are sensitive as a whole.
+func isTrue(raw json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(raw), []byte("true"))
+}
+
+// anyTrue returns whether raw is true, or a JSON object or array holding
+// true at any depth, as in the sensitivity and unknown markers of a plan.
+func anyTrue(raw json.RawMessage) bool {
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return false
+	}
+	return valueAnyTrue(v)
+}
+
+func valueAnyTrue(v interface{}) bool {
+	switch t := v.(type) {
+	case bool:
+		return t
+	case []interface{}:
+		for _, e := range t {
+			if valueAnyTrue(e) {
+				return true
+			}
+		}
+	case map[string]interface{}:
+		for _, e := range t {
+			if valueAnyTrue(e) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return bytes.Equal(a, b)
+	}
+	ca, _ := json.Marshal(va)
+	cb, _ := json.Marshal(vb)
+	return bytes.Equal(ca, cb)
+}
+
+// formatPath renders an attribute path of a plan, e.g. tags["Name"] or
+// ebs_block_device[0].
+func formatPath(path []interface{}) string {
+	var sb strings.Builder
+	for i, step := range path {
+		switch s := step.(type) {
+		case string:
+			if i == 0 {
+				sb.WriteString(s)
+			} else {
+				fmt.Fprintf(&sb, "[%q]", s)
+			}
+		case float64:
+			fmt.Fprintf(&sb, "[%d]", int64(s))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,177 @@
+package opentofu
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseResourceChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		plan    string
+		want    []ResourceChange
+		wantErr bool
+	}{
+		{
+			name: "no-op resources are omitted",
+			plan: `{"resource_changes": [{"address": "a.same", "type": "a", "change": {"actions": ["no-op"]}}]}`,
+			want: []ResourceChange{},
+		},
+		{
+			name: "create",
+			plan: `{"resource_changes": [{
+				"address": "aws_instance.web", "type": "aws_instance",
+				"provider_name": "registry.opentofu.org/hashicorp/aws",
+				"change": {
+					"actions": ["create"],
+					"before": null,
+					"after": {"ami": "ami-1", "tags": null},
+					"after_unknown": {"id": true}
+				}
+			}]}`,
+			want: []ResourceChange{{
+				Address:  "aws_instance.web",
+				Type:     "aws_instance",
+				Action:   "create",
+				Provider: "registry.opentofu.org/hashicorp/aws",
+				Attributes: map[string]AttributeChange{
+					"ami": {After: json.RawMessage(`"ami-1"`)},
+					"id":  {After: unknownValue},
+				},
+			}},
+		},
+		{
+			name: "replace",
+			plan: `{"resource_changes": [{
+				"address": "aws_instance.web", "type": "aws_instance",
+				"action_reason": "replace_because_cannot_update",
+				"change": {
+					"actions": ["delete", "create"],
+					"before": {"ami": "ami-1", "tags": {"Name": "web"}},
+					"after": {"ami": "ami-2", "tags": {"Name": "web"}},
+					"replace_paths": [["ami"], ["tags", "Name"], ["ebs_block_device", 0]]
+				}
+			}]}`,
+			want: []ResourceChange{{
+				Address:      "aws_instance.web",
+				Type:         "aws_instance",
+				Action:       "replace",
+				ActionReason: "replace_because_cannot_update",
+				ReplacePaths: []string{"ami", `tags["Name"]`, "ebs_block_device[0]"},
+				Attributes: map[string]AttributeChange{
+					"ami": {Before: json.RawMessage(`"ami-1"`), After: json.RawMessage(`"ami-2"`)},
+				},
+			}},
+		},
+		{
+			name: "delete has no attributes",
+			plan: `{"resource_changes": [{
+				"address": "a.gone", "type": "a",
+				"change": {"actions": ["delete"], "before": {"name": "x"}, "after": null}
+			}]}`,
+			want: []ResourceChange{{Address: "a.gone", Type: "a", Action: "delete"}},
+		},
+		{
+			name: "sensitive values are redacted",
+			plan: `{"resource_changes": [{
+				"address": "aws_db_instance.db", "type": "aws_db_instance",
+				"change": {
+					"actions": ["update"],
+					"before": {"password": "old", "settings": {"user": "u", "token": "t1"}, "size": 1},
+					"after": {"password": "new", "settings": {"user": "u", "token": "t2"}, "size": 2},
+					"before_sensitive": {"password": true, "settings": {"token": true}},
+					"after_sensitive": {"password": true, "settings": {"token": true}}
+				}
+			}]}`,
+			want: []ResourceChange{{
+				Address: "aws_db_instance.db",
+				Type:    "aws_db_instance",
+				Action:  "update",
+				Attributes: map[string]AttributeChange{
+					"password": {Before: sensitiveValue, After: sensitiveValue},
+					"settings": {Before: sensitiveValue, After: sensitiveValue},
+					"size":     {Before: json.RawMessage(`1`), After: json.RawMessage(`2`)},
+				},
+			}},
+		},
+		{
+			name: "sensitive resource values are redacted",
+			plan: `{"resource_changes": [{
+				"address": "a.b", "type": "a",
+				"change": {
+					"actions": ["update"],
+					"before": {"password": "old", "size": 1},
+					"after": {"password": "new", "size": 2},
+					"before_sensitive": true,
+					"after_sensitive": true
+				}
+			}]}`,
+			want: []ResourceChange{{
+				Address: "a.b",
+				Type:    "a",
+				Action:  "update",
+				Attributes: map[string]AttributeChange{
+					"password": {Before: sensitiveValue, After: sensitiveValue},
+					"size":     {Before: sensitiveValue, After: sensitiveValue},
+				},
+			}},
+		},
+		{
+			name: "values becoming sensitive are redacted after the change only",
+			plan: `{"resource_changes": [{
+				"address": "a.b", "type": "a",
+				"change": {
+					"actions": ["update"],
+					"before": {"value": "public"},
+					"after": {"value": "secret"},
+					"before_sensitive": {},
+					"after_sensitive": {"value": true}
+				}
+			}]}`,
+			want: []ResourceChange{{
+				Address: "a.b",
+				Type:    "a",
+				Action:  "update",
+				Attributes: map[string]AttributeChange{
+					"value": {Before: json.RawMessage(`"public"`), After: sensitiveValue},
+				},
+			}},
+		},
+		{
+			name: "unchanged attributes are omitted",
+			plan: `{"resource_changes": [{
+				"address": "a.b", "type": "a",
+				"change": {
+					"actions": ["update"],
+					"before": {"tags": {"a": "1", "b": "2"}, "name": "x"},
+					"after": {"tags": {"b": "2", "a": "1"}, "name": "y"}
+				}
+			}]}`,
+			want: []ResourceChange{{
+				Address: "a.b",
+				Type:    "a",
+				Action:  "update",
+				Attributes: map[string]AttributeChange{
+					"name": {Before: json.RawMessage(`"x"`), After: json.RawMessage(`"y"`)},
+				},
+			}},
+		},
+		{
+			name:    "no plan",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseResourceChanges([]byte(tt.plan))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseResourceChanges() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseResourceChanges() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package opentofu
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	connectorv1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/tfconnector/v1alpha1"
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+	"github.com/krateoplatformops/opentofu-provider/internal/controllers/resolvers"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const connectorOverrideFile = "krateo_connector_override.tf"
+
+// resolveConnectorConfiguration writes the HCL configuration of the
+// TFConnector, if any, next to the module as an override file.
+func resolveConnectorConfiguration(ctx context.Context, kube client.Client, cfg *connectorv1alpha1.TFConnector, files *runnerFiles) error {
+	parts := []string{}
+
+	if cfg.Spec.Configuration != nil {
+		parts = append(parts, *cfg.Spec.Configuration)
+	}
+
+	if ref := cfg.Spec.ConfigurationRef; ref != nil {
+		value, err := resolvers.GetConfigMapValue(ctx, kube, &workspacev1alpha1.KeyReference{
+			Namespace: cfg.GetNamespace(),
+			Name:      ref.Name,
+			Key:       ref.Key,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to resolve connector configuration: %w", err)
+		}
+		parts = append(parts, value)
+	}
+
+	if len(parts) == 0 {
+		return nil
+	}
+
+	files.AddToModule(connectorOverrideFile, []byte(strings.Join(parts, "\n")), connectorOverrideFile)
+	return nil
+}
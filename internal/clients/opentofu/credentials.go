@@ -0,0 +1,89 @@
+package opentofu
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	connectorv1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/tfconnector/v1alpha1"
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+	"github.com/krateoplatformops/opentofu-provider/internal/controllers/resolvers"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var tokenHostname = regexp.MustCompile(`^[A-Za-z0-9.-]+$`)
+
+// resolveCredentialsFiles resolves the provider credentials files of the
+// TFConnector into runner files copied next to the module.
+func resolveCredentialsFiles(ctx context.Context, kube client.Client, cfg *connectorv1alpha1.TFConnector, files *runnerFiles) error {
+	for i, f := range cfg.Spec.ProvidersCredentials.Files {
+		filename, err := validateCredentialsFilename(f.Filename)
+		if err != nil {
+			return err
+		}
+
+		content, err := resolvers.GetSecretValue(ctx, kube, &workspacev1alpha1.KeyReference{
+			Namespace: cfg.GetNamespace(),
+			Name:      f.SecretKeyRef.Name,
+			Key:       f.SecretKeyRef.Key,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to resolve credentials file %s: %w", f.Filename, err)
+		}
+
+		files.AddToModule(fmt.Sprintf("credentials-%d", i), content, filename)
+	}
+
+	return nil
+}
+
+// resolveBackendCredentials resolves the API tokens of the TFConnector into
+// the TF_TOKEN_<hostname> env vars of the runner container.
+func resolveBackendCredentials(ctx context.Context, kube client.Client, cfg *connectorv1alpha1.TFConnector, files *runnerFiles) error {
+	for i, c := range cfg.Spec.BackendCredentials {
+		env, err := TokenEnvVar(c.Hostname)
+		if err != nil {
+			return err
+		}
+
+		token, err := resolvers.GetSecretValue(ctx, kube, &workspacev1alpha1.KeyReference{
+			Namespace: cfg.GetNamespace(),
+			Name:      c.SecretKeyRef.Name,
+			Key:       c.SecretKeyRef.Key,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to resolve credentials of %s: %w", c.Hostname, err)
+		}
+
+		files.AddEnv(env, fmt.Sprintf("token-%d", i), token)
+	}
+
+	return nil
+}
+
+// TokenEnvVar returns the name of the env var OpenTofu reads the API token
+// of hostname from: dots are replaced with underscores and dashes with
+// double underscores, e.g. TF_TOKEN_app_terraform_io.
+func TokenEnvVar(hostname string) (string, error) {
+	if !tokenHostname.MatchString(hostname) {
+		return "", fmt.Errorf("invalid backend credentials hostname %q", hostname)
+	}
+	return "TF_TOKEN_" + strings.NewReplacer(".", "_", "-", "__").Replace(hostname), nil
+}
+
+// validateCredentialsFilename returns the filename cleaned up, or an error
+// when it does not name a file within the module.
+func validateCredentialsFilename(filename string) (string, error) {
+	if filename == "" || path.IsAbs(filename) {
+		return "", fmt.Errorf("credentials filename %q must be a relative path", filename)
+	}
+
+	clean := path.Clean(filename)
+	if clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("credentials filename %q points outside of the module", filename)
+	}
+
+	return clean, nil
+}
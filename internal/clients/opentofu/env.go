@@ -0,0 +1,104 @@
+package opentofu
+
+import (
+	"context"
+	"sort"
+
+	connectorv1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/tfconnector/v1alpha1"
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+	"github.com/krateoplatformops/opentofu-provider/internal/controllers/resolvers"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// connectorEnvFrom returns the sources of env vars of the TFConnector.
+func connectorEnvFrom(cfg *connectorv1alpha1.TFConnector) []corev1.EnvFromSource {
+	var envs []corev1.EnvFromSource
+	envs = append(envs, cfg.Spec.EnvVars...)
+	envs = append(envs, cfg.Spec.ProvidersCredentials.EnvVars...)
+	return envs
+}
+
+// mergeEnv returns the env vars of the runner container: those of the
+// Workspace replace the generated ones with the same name.
+func mergeEnv(generated, workspace []corev1.EnvVar) []corev1.EnvVar {
+	names := map[string]bool{}
+	for _, e := range workspace {
+		names[e.Name] = true
+	}
+
+	var env []corev1.EnvVar
+	for _, e := range generated {
+		if !names[e.Name] {
+			env = append(env, e)
+		}
+	}
+	return append(env, workspace...)
+}
+
+// EnvConflicts returns the names of the env vars set by both the TFConnector
+// and the Workspace. Sources that cannot be read, e.g. missing optional
+// ones, are skipped.
+func EnvConflicts(ctx context.Context, kube client.Client, cr workspacev1alpha1.Workspace) ([]string, error) {
+	params := cr.Spec.Workspace
+	if len(params.Env) == 0 && len(params.EnvFrom) == 0 {
+		return nil, nil
+	}
+
+	cfg, err := resolvers.ResolveTFConnector(ctx, kube, cr.Spec.TFConnectorRef)
+	if err != nil {
+		return nil, err
+	}
+
+	connector := envFromKeys(ctx, kube, cr.GetNamespace(), connectorEnvFrom(cfg))
+	for _, c := range cfg.Spec.BackendCredentials {
+		if env, err := TokenEnvVar(c.Hostname); err == nil {
+			connector[env] = true
+		}
+	}
+
+	workspace := envFromKeys(ctx, kube, cr.GetNamespace(), params.EnvFrom)
+	for _, e := range params.Env {
+		workspace[e.Name] = true
+	}
+
+	conflicts := []string{}
+	for name := range workspace {
+		if connector[name] {
+			conflicts = append(conflicts, name)
+		}
+	}
+	sort.Strings(conflicts)
+
+	return conflicts, nil
+}
+
+// envFromKeys returns the names of the env vars set by sources, which are
+// in namespace, like the runner Pod.
+func envFromKeys(ctx context.Context, kube client.Client, namespace string, sources []corev1.EnvFromSource) map[string]bool {
+	keys := map[string]bool{}
+	for _, src := range sources {
+		switch {
+		case src.ConfigMapRef != nil:
+			cm := corev1.ConfigMap{}
+			if err := kube.Get(ctx, client.ObjectKey{Namespace: namespace, Name: src.ConfigMapRef.Name}, &cm); err != nil {
+				continue
+			}
+			for k := range cm.Data {
+				keys[src.Prefix+k] = true
+			}
+			for k := range cm.BinaryData {
+				keys[src.Prefix+k] = true
+			}
+		case src.SecretRef != nil:
+			sec := corev1.Secret{}
+			if err := kube.Get(ctx, client.ObjectKey{Namespace: namespace, Name: src.SecretRef.Name}, &sec); err != nil {
+				continue
+			}
+			for k := range sec.Data {
+				keys[src.Prefix+k] = true
+			}
+		}
+	}
+	return keys
+}
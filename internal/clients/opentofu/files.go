@@ -0,0 +1,117 @@
+package opentofu
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	retry "github.com/avast/retry-go/v4"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	runnerFilesVolume = "runner-files"
+	runnerFilesPath   = "/opt/krateo/files"
+)
+
+// runnerFiles are the files generated by the controller for a runner Job.
+// Since they may hold sensitive values they are stored in a Secret mounted
+// read-only into the runner container.
+type runnerFiles struct {
+	data map[string][]byte
+	// copies are the commands copying files into the module directory.
+	copies []string
+	// envs maps the env vars of the runner container to the files holding
+	// their value.
+	envs [][2]string
+}
+
+func newRunnerFiles() *runnerFiles {
+	return &runnerFiles{data: map[string][]byte{}}
+}
+
+// Add stores a file and returns its path in the runner container.
+func (f *runnerFiles) Add(name string, content []byte) string {
+	f.data[name] = content
+	return path.Join(runnerFilesPath, name)
+}
+
+// AddToModule stores a file that is copied to dest, a path relative to the
+// module directory, before OpenTofu runs.
+func (f *runnerFiles) AddToModule(name string, content []byte, dest string) {
+	src := f.Add(name, content)
+	f.copies = append(f.copies, fmt.Sprintf("mkdir -p %s && cp %s %s", shellQuote(path.Dir(dest)), src, shellQuote(dest)))
+}
+
+// AddEnv stores a value that is set as the env var env of the runner
+// container.
+func (f *runnerFiles) AddEnv(env, name string, content []byte) {
+	f.data[name] = content
+	f.envs = append(f.envs, [2]string{env, name})
+}
+
+// EnvVars returns the env vars added with AddEnv, read from secretName.
+func (f *runnerFiles) EnvVars(secretName string) []corev1.EnvVar {
+	envs := make([]corev1.EnvVar, 0, len(f.envs))
+	for _, e := range f.envs {
+		envs = append(envs, corev1.EnvVar{
+			Name: e[0],
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+					Key:                  e[1],
+				},
+			},
+		})
+	}
+	return envs
+}
+
+// CopyCMDs returns the commands copying the files added with AddToModule
+// into the module directory.
+func (f *runnerFiles) CopyCMDs() []string {
+	return f.copies
+}
+
+func (f *runnerFiles) Empty() bool {
+	return len(f.data) == 0
+}
+
+func (r *JobRunner) generateFilesSecret(files *runnerFiles) *corev1.Secret {
+	sec := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.Metadata.Name,
+			Namespace: r.Metadata.Namespace,
+		},
+		Data: files.data,
+	}
+	return sec
+}
+
+func InstallSecret(ctx context.Context, kube client.Client, obj *corev1.Secret) error {
+	return retry.Do(
+		func() error {
+			tmp := corev1.Secret{}
+			err := kube.Get(ctx, client.ObjectKeyFromObject(obj), &tmp)
+			if err != nil {
+				if apierrors.IsNotFound(err) {
+					return kube.Create(ctx, obj)
+				}
+
+				return err
+			}
+
+			// A leftover secret of a previous run may hold stale files.
+			tmp.Data = obj.Data
+			if err := kube.Update(ctx, &tmp); err != nil {
+				return err
+			}
+			tmp.DeepCopyInto(obj)
+
+			return nil
+		},
+	)
+}
@@ -0,0 +1,56 @@
+package opentofu
+
+import (
+	"fmt"
+	"path"
+
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+)
+
+// CheckGuardrails returns the reasons why the guardrails of a Workspace
+// block applying a plan, if any.
+func CheckGuardrails(g *workspacev1alpha1.Guardrails, changes []ResourceChange, planned *workspacev1alpha1.PlannedChanges) ([]string, error) {
+	if g == nil {
+		return nil, nil
+	}
+
+	violations := []string{}
+	for _, c := range changes {
+		if c.Action != "delete" && c.Action != "replace" {
+			continue
+		}
+		for _, pattern := range g.ProtectedResources {
+			matched, err := matchResource(pattern, c)
+			if err != nil {
+				return nil, err
+			}
+			if matched {
+				violations = append(violations, fmt.Sprintf("%s of protected resource %s", c.Action, c.Address))
+				break
+			}
+		}
+	}
+
+	if g.MaxChanges != nil && planned != nil {
+		if total := planned.Add + planned.Change + planned.Destroy; total > *g.MaxChanges {
+			violations = append(violations, fmt.Sprintf("%d changes exceed the maximum of %d", total, *g.MaxChanges))
+		}
+	}
+
+	return violations, nil
+}
+
+// matchResource returns whether pattern matches the address or the type of
+// the resource of a change.
+func matchResource(pattern string, c ResourceChange) (bool, error) {
+	for _, name := range []string{c.Address, c.Type} {
+		matched, err := path.Match(pattern, name)
+		if err != nil {
+			return false, fmt.Errorf("invalid protected resource pattern %q: %w", pattern, err)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
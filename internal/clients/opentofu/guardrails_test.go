@@ -0,0 +1,118 @@
+package opentofu
+
+import (
+	"reflect"
+	"testing"
+
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+)
+
+func TestCheckGuardrails(t *testing.T) {
+	changes := []ResourceChange{
+		{Address: "aws_db_instance.main", Type: "aws_db_instance", Action: "delete"},
+		{Address: "module.network.aws_vpc.main", Type: "aws_vpc", Action: "replace"},
+		{Address: "aws_instance.web", Type: "aws_instance", Action: "update"},
+		{Address: "aws_s3_bucket.logs", Type: "aws_s3_bucket", Action: "create"},
+	}
+
+	tests := []struct {
+		name       string
+		guardrails *workspacev1alpha1.Guardrails
+		changes    []ResourceChange
+		planned    *workspacev1alpha1.PlannedChanges
+		want       []string
+		wantErr    bool
+	}{
+		{
+			name: "no guardrails",
+			want: nil,
+		},
+		{
+			name:       "protected type deleted",
+			guardrails: &workspacev1alpha1.Guardrails{ProtectedResources: []string{"aws_db_instance"}},
+			changes:    changes,
+			want:       []string{"delete of protected resource aws_db_instance.main"},
+		},
+		{
+			name:       "protected module replaced",
+			guardrails: &workspacev1alpha1.Guardrails{ProtectedResources: []string{"module.network.*"}},
+			changes:    changes,
+			want:       []string{"replace of protected resource module.network.aws_vpc.main"},
+		},
+		{
+			name:       "updates and creates are allowed",
+			guardrails: &workspacev1alpha1.Guardrails{ProtectedResources: []string{"aws_instance.*", "aws_s3_bucket"}},
+			changes:    changes,
+			want:       []string{},
+		},
+		{
+			name:       "a resource matching several patterns is reported once",
+			guardrails: &workspacev1alpha1.Guardrails{ProtectedResources: []string{"aws_db_instance", "aws_db_instance.*"}},
+			changes:    changes,
+			want:       []string{"delete of protected resource aws_db_instance.main"},
+		},
+		{
+			name:       "too many changes",
+			guardrails: &workspacev1alpha1.Guardrails{MaxChanges: int32Ptr(3)},
+			changes:    changes,
+			planned:    &workspacev1alpha1.PlannedChanges{Add: 2, Change: 1, Destroy: 2},
+			want:       []string{"5 changes exceed the maximum of 3"},
+		},
+		{
+			name:       "changes within the maximum",
+			guardrails: &workspacev1alpha1.Guardrails{MaxChanges: int32Ptr(5)},
+			changes:    changes,
+			planned:    &workspacev1alpha1.PlannedChanges{Add: 2, Change: 1, Destroy: 2},
+			want:       []string{},
+		},
+		{
+			name:       "invalid pattern",
+			guardrails: &workspacev1alpha1.Guardrails{ProtectedResources: []string{"aws_db_instance["}},
+			changes:    changes,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CheckGuardrails(tt.guardrails, tt.changes, tt.planned)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckGuardrails() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckGuardrails() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchResource(t *testing.T) {
+	change := ResourceChange{Address: `module.db.aws_db_instance.main["eu"]`, Type: "aws_db_instance"}
+
+	tests := []struct {
+		pattern string
+		want    bool
+		wantErr bool
+	}{
+		{pattern: "aws_db_instance", want: true},
+		{pattern: "aws_db_*", want: true},
+		{pattern: "module.db.*", want: true},
+		{pattern: `module.db.aws_db_instance.main["eu"]`, want: false},
+		{pattern: `module.db.aws_db_instance.main\["eu"\]`, want: true},
+		{pattern: "aws_instance", want: false},
+		{pattern: "module.network.*", want: false},
+		{pattern: "[", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			got, err := matchResource(tt.pattern, change)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("matchResource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("matchResource(%q) = %v, want %v", tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package opentofu
+
+import (
+	"fmt"
+	"regexp"
+
+	connectorv1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/tfconnector/v1alpha1"
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// DefaultRepository is the repository of the OpenTofu image.
+	DefaultRepository = "ghcr.io/opentofu/opentofu"
+
+	// DefaultVersion is the version of OpenTofu run when neither the
+	// Workspace nor its TFConnector choose one. OCI module sources need
+	// at least 1.10.
+	DefaultVersion = "1.10.6"
+
+	// DefaultGitImage is the image cloning git modules.
+	DefaultGitImage = "alpine/git:latest"
+
+	// DefaultReporterRepository is the repository of the image of the
+	// provider, which also ships the reporter of the runner Jobs.
+	DefaultReporterRepository = "ghcr.io/krateoplatformops/opentofu-provider"
+)
+
+// Version of the provider, set at build time. The default reporter image
+// has the same version, so that it writes reports the provider can read.
+var Version = "latest"
+
+// DefaultReporterImage returns the reporter image of the same version as
+// the provider.
+func DefaultReporterImage() string {
+	return fmt.Sprintf("%s:%s", DefaultReporterRepository, Version)
+}
+
+// RunnerDefaults are the settings of the runner Jobs used when neither the
+// Workspace nor its TFConnector set them.
+type RunnerDefaults struct {
+	// Repository of the OpenTofu image.
+	Repository string
+
+	// Version of OpenTofu, i.e. the tag of its image.
+	Version string
+
+	// GitImage is the image cloning git modules.
+	GitImage string
+
+	// ReporterImage is the image storing the report of the runner Jobs.
+	ReporterImage string
+
+	// ImagePullSecrets are the names of the Secrets, in the namespace of
+	// each Workspace, used to pull the images.
+	ImagePullSecrets []string
+}
+
+// runnerImages are the images of the containers of a runner Job.
+type runnerImages struct {
+	// OpenTofu is the image of the runner container and of the fetchers
+	// that do not use git.
+	OpenTofu string
+	// Git is the image of the fetcher cloning git modules.
+	Git string
+	// Reporter is the image of the reporter container.
+	Reporter string
+	// PullSecrets are the Secrets used to pull them.
+	PullSecrets []corev1.LocalObjectReference
+}
+
+// resolveImages returns the images of the runner Job of a Workspace.
+func resolveImages(cr workspacev1alpha1.Workspace, cfg *connectorv1alpha1.TFConnector, defaults RunnerDefaults) runnerImages {
+	images := runnerImages{
+		OpenTofu: opentofuImage(cr, cfg, defaults),
+		Git:      cfg.Spec.GitImage,
+		Reporter: defaults.ReporterImage,
+	}
+	if images.Git == "" {
+		images.Git = defaults.GitImage
+	}
+	if images.Git == "" {
+		images.Git = DefaultGitImage
+	}
+	if images.Reporter == "" {
+		images.Reporter = DefaultReporterImage()
+	}
+
+	seen := map[string]bool{}
+	names := append(append([]string{}, defaults.ImagePullSecrets...), imagePullSecretNames(cfg)...)
+	for _, name := range names {
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		images.PullSecrets = append(images.PullSecrets, corev1.LocalObjectReference{Name: name})
+	}
+
+	return images
+}
+
+func imagePullSecretNames(cfg *connectorv1alpha1.TFConnector) []string {
+	names := make([]string, 0, len(cfg.Spec.ImagePullSecrets))
+	for _, ref := range cfg.Spec.ImagePullSecrets {
+		names = append(names, ref.Name)
+	}
+	return names
+}
+
+// WithDefaults sets the defaults of the runner Job of a Run.
+func WithDefaults(d RunnerDefaults) RunOption {
+	return func(o *runOptions) {
+		o.defaults = d
+	}
+}
+
+// opentofuImage returns the OpenTofu image of the runner of a Workspace.
+// The settings of the Workspace, if any, override those of the TFConnector;
+// at either level the image takes precedence over the version.
+func opentofuImage(cr workspacev1alpha1.Workspace, cfg *connectorv1alpha1.TFConnector, defaults RunnerDefaults) string {
+	image, version := cfg.Spec.Image, cfg.Spec.Version
+	if cr.Spec.Workspace.Image != "" || cr.Spec.Workspace.Version != "" {
+		image, version = cr.Spec.Workspace.Image, cr.Spec.Workspace.Version
+	}
+
+	if image != "" {
+		return image
+	}
+	if version == "" {
+		version = defaults.Version
+	}
+	if version == "" {
+		version = DefaultVersion
+	}
+	repository := defaults.Repository
+	if repository == "" {
+		repository = DefaultRepository
+	}
+	return fmt.Sprintf("%s:%s", repository, version)
+}
+
+// The runner prints the version of OpenTofu to its logs before running any
+// other command.
+const versionCMD = "tofu version"
+
+var tfVersion = regexp.MustCompile(`(?m)^OpenTofu v(\S+)$`)
+
+// ParseVersion returns the version of OpenTofu printed to the logs of a
+// runner Job, if any.
+func ParseVersion(logs string) string {
+	if m := tfVersion.FindStringSubmatch(logs); len(m) > 1 {
+		return m[1]
+	}
+	return ""
+}
@@ -0,0 +1,314 @@
+package opentofu
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	retry "github.com/avast/retry-go/v4"
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// volumePath is where the runner volume is mounted in every container.
+	volumePath = "/mnt"
+	// workspacePath is the directory of the runner volume holding the root module.
+	workspacePath = volumePath + "/workspace"
+	// commitFile is the file of the runner volume holding the SHA of the
+	// commit checked out by git.
+	commitFile = volumePath + "/commit"
+
+	inlineModuleVolume = "inline-module"
+	inlineModulePath   = "/opt/krateo/module"
+	inlineModuleFile   = "main.tf"
+)
+
+func (r *JobRunner) generateModuleConfigMap(module string) *corev1.ConfigMap {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.Metadata.Name,
+			Namespace: r.Metadata.Namespace,
+		},
+		Data: map[string]string{
+			inlineModuleFile: module,
+		},
+	}
+	return cm
+}
+
+// moduleFetcher describes how the root module of a workspace is materialised
+// into the runner volume before OpenTofu runs.
+type moduleFetcher struct {
+	// Container is the init container that writes the module to workspacePath.
+	Container corev1.Container
+	// Volumes required by the init container, besides the runner volume.
+	Volumes []corev1.Volume
+	// Objects created for the fetcher; they are owned by the runner Job.
+	Objects []client.Object
+}
+
+// fetchModule returns the fetcher of the workspace module. gitEnvs are the
+// environment of git clones, envs the one of the runner container.
+func (r *JobRunner) fetchModule(ctx context.Context, kube client.Client, cr workspacev1alpha1.Workspace, images runnerImages, mount corev1.VolumeMount, gitEnvs, envs []corev1.EnvFromSource) (*moduleFetcher, error) {
+	name := fmt.Sprintf("%s-init", r.Metadata.Name)
+
+	switch cr.Spec.Workspace.Source {
+	case workspacev1alpha1.ModuleSourceInline:
+		cm := r.generateModuleConfigMap(cr.Spec.Workspace.Module)
+		if err := InstallConfigMap(ctx, kube, cm); err != nil {
+			return nil, fmt.Errorf("failed to create inline module config map: %w", err)
+		}
+
+		copyCommand := fmt.Sprintf("mkdir -p %s && cp %s/%s %s/", workspacePath, inlineModulePath, inlineModuleFile, workspacePath)
+
+		return &moduleFetcher{
+			Container: corev1.Container{
+				Name:       name,
+				Image:      images.OpenTofu,
+				WorkingDir: volumePath,
+				VolumeMounts: []corev1.VolumeMount{
+					mount,
+					{
+						Name:      inlineModuleVolume,
+						MountPath: inlineModulePath,
+						ReadOnly:  true,
+					},
+				},
+				Command: []string{"sh", "-c"},
+				Args:    []string{copyCommand},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: inlineModuleVolume,
+					VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{Name: cm.GetName()},
+						},
+					},
+				},
+			},
+			Objects: []client.Object{cm},
+		}, nil
+	case workspacev1alpha1.ModuleSourceRemote, "":
+		fetcher := &moduleFetcher{
+			Container: corev1.Container{
+				Name:         name,
+				WorkingDir:   volumePath,
+				VolumeMounts: []corev1.VolumeMount{mount},
+				Command:      []string{"sh", "-c"},
+			},
+		}
+
+		module := cr.Spec.Workspace.Module
+		switch scheme := DetectModuleScheme(module); scheme {
+		case ModuleSchemeArchive:
+			fetchCommand, err := archiveFetchCommand(module, cr.Spec.Workspace.Archive)
+			if err != nil {
+				return nil, err
+			}
+			fetcher.Container.Image = images.OpenTofu
+			fetcher.Container.Args = []string{fetchCommand}
+		case ModuleSchemeGetter:
+			// OpenTofu itself downloads the module, using the same environment as
+			// the runner container so that e.g. bucket credentials are available.
+			fetchCommand := fmt.Sprintf("mkdir -p workspace && cd workspace && tofu init -no-color -input=false -backend=false -from-module=%s", shellQuote(module))
+			fetcher.Container.Image = images.OpenTofu
+			fetcher.Container.EnvFrom = envs
+			fetcher.Container.Args = []string{fetchCommand}
+		default:
+			cloneCommand, err := gitCloneCommand(strings.TrimPrefix(module, "git::"), cr.Spec.Workspace.Git)
+			if err != nil {
+				return nil, err
+			}
+			fetcher.Container.Image = images.Git
+			fetcher.Container.EnvFrom = gitEnvs
+			fetcher.Container.Args = []string{cloneCommand}
+		}
+
+		return fetcher, nil
+	default:
+		return nil, fmt.Errorf("unsupported module source: %s", cr.Spec.Workspace.Source)
+	}
+}
+
+// A ModuleScheme identifies how a Remote module is fetched.
+type ModuleScheme string
+
+const (
+	// ModuleSchemeGit modules are cloned with git.
+	ModuleSchemeGit ModuleScheme = "git"
+	// ModuleSchemeArchive modules are archives downloaded over HTTP(S).
+	ModuleSchemeArchive ModuleScheme = "archive"
+	// ModuleSchemeGetter modules are downloaded by tofu init -from-module,
+	// e.g. s3::, gcs:: and oci:// addresses.
+	ModuleSchemeGetter ModuleScheme = "getter"
+)
+
+var getterPrefixes = []string{"s3::", "gcs::", "oci://"}
+
+// archiveExtensions maps the supported archive extensions to the command
+// extracting an archive into the workspace directory.
+var archiveExtensions = map[string]string{
+	".zip":    "unzip -q %s -d workspace",
+	".tar":    "tar -xf %s -C workspace",
+	".tar.gz": "tar -xzf %s -C workspace",
+	".tgz":    "tar -xzf %s -C workspace",
+}
+
+// DetectModuleScheme returns the scheme of a Remote module address. Addresses
+// that are not recognised are assumed to be git repositories.
+func DetectModuleScheme(module string) ModuleScheme {
+	for _, prefix := range getterPrefixes {
+		if strings.HasPrefix(module, prefix) {
+			return ModuleSchemeGetter
+		}
+	}
+	if archiveExtension(module) != "" {
+		return ModuleSchemeArchive
+	}
+	return ModuleSchemeGit
+}
+
+// archiveExtension returns the archive extension of an HTTP(S) module
+// address, or an empty string if it does not point to a supported archive.
+func archiveExtension(module string) string {
+	u, err := url.Parse(module)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	for ext := range archiveExtensions {
+		if strings.HasSuffix(u.Path, ext) {
+			return ext
+		}
+	}
+	return ""
+}
+
+var archiveChecksum = regexp.MustCompile(`^sha256:([0-9a-f]{64})$`)
+
+// archiveFetchCommand returns the commands downloading the module archive,
+// verifying its checksum when one is set, and extracting it into the
+// workspace directory.
+func archiveFetchCommand(module string, opts *workspacev1alpha1.ArchiveSource) (string, error) {
+	archive := path.Join(volumePath, "module-archive")
+	cmds := []string{
+		"mkdir -p workspace",
+		fmt.Sprintf("wget -q -O %s %s", archive, shellQuote(module)),
+	}
+
+	if opts != nil && opts.Checksum != "" {
+		m := archiveChecksum.FindStringSubmatch(opts.Checksum)
+		if m == nil {
+			return "", fmt.Errorf("invalid archive checksum %q", opts.Checksum)
+		}
+		cmds = append(cmds, fmt.Sprintf("echo '%s  %s' | sha256sum -c -", m[1], archive))
+	}
+
+	cmds = append(cmds,
+		fmt.Sprintf(archiveExtensions[archiveExtension(module)], archive),
+		fmt.Sprintf("rm %s", archive),
+	)
+
+	return strings.Join(cmds, " && "), nil
+}
+
+var gitCommit = regexp.MustCompile(`^[0-9a-fA-F]{7,64}$`)
+
+// gitCloneCommand returns the commands cloning the module repository into
+// the workspace directory and checking out the requested revision. The SHA
+// of the checked out commit is written as termination message of the
+// container, where GetCommit reads it from.
+func gitCloneCommand(module string, opts *workspacev1alpha1.GitSource) (string, error) {
+	if opts == nil {
+		opts = &workspacev1alpha1.GitSource{}
+	}
+	if strings.HasPrefix(opts.Ref, "-") {
+		return "", fmt.Errorf("invalid git ref %q", opts.Ref)
+	}
+	if opts.Commit != "" && !gitCommit.MatchString(opts.Commit) {
+		return "", fmt.Errorf("invalid git commit %q", opts.Commit)
+	}
+	// Shallow fetches only resolve full SHAs, of SHA-1 or SHA-256
+	// repositories.
+	if opts.Commit != "" && opts.Depth != nil && len(opts.Commit) != 40 && len(opts.Commit) != 64 {
+		return "", fmt.Errorf("git commit %q must be a full SHA when depth is set", opts.Commit)
+	}
+
+	depth := ""
+	if opts.Depth != nil {
+		depth = fmt.Sprintf(" --depth %d", *opts.Depth)
+	}
+
+	clone := "git clone -q" + depth
+	if opts.Ref != "" {
+		clone += " --branch " + shellQuote(opts.Ref)
+	}
+	clone += fmt.Sprintf(" %s workspace", shellQuote(module))
+
+	cmds := []string{
+		"git config --global credential.helper '!f() { echo username=author; echo \"password=$GIT_CREDENTIALS\"; };f'",
+		clone,
+	}
+	if opts.Commit != "" {
+		if opts.Depth != nil {
+			cmds = append(cmds, fmt.Sprintf("git -C workspace fetch -q%s origin %s", depth, opts.Commit))
+		}
+		cmds = append(cmds, fmt.Sprintf("git -C workspace checkout -q %s", opts.Commit))
+	}
+	if opts.RecurseSubmodules {
+		cmds = append(cmds, "git -C workspace submodule update -q --init --recursive"+depth)
+	}
+	cmds = append(cmds, "git -C workspace rev-parse HEAD | tee /dev/termination-log "+commitFile)
+
+	return strings.Join(cmds, " && "), nil
+}
+
+// ValidateEntrypoint returns the entrypoint of the module cleaned up and
+// relative to the module root, or an error when it points outside of it.
+func ValidateEntrypoint(entrypoint string) (string, error) {
+	if entrypoint == "" {
+		return ".", nil
+	}
+	if path.IsAbs(entrypoint) {
+		return "", fmt.Errorf("entrypoint %q must be a relative path", entrypoint)
+	}
+
+	clean := path.Clean(entrypoint)
+	if clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("entrypoint %q points outside of the module", entrypoint)
+	}
+
+	return clean, nil
+}
+
+func InstallConfigMap(ctx context.Context, kube client.Client, obj *corev1.ConfigMap) error {
+	return retry.Do(
+		func() error {
+			tmp := corev1.ConfigMap{}
+			err := kube.Get(ctx, client.ObjectKeyFromObject(obj), &tmp)
+			if err != nil {
+				if apierrors.IsNotFound(err) {
+					return kube.Create(ctx, obj)
+				}
+
+				return err
+			}
+
+			// A leftover config map of a previous run may hold a stale module.
+			tmp.Data = obj.Data
+			if err := kube.Update(ctx, &tmp); err != nil {
+				return err
+			}
+			tmp.DeepCopyInto(obj)
+
+			return nil
+		},
+	)
+}
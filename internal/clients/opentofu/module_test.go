@@ -0,0 +1,61 @@
+package opentofu
+
+import (
+	"strings"
+	"testing"
+
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+)
+
+func TestGitCloneCommand(t *testing.T) {
+	const sha = "3f2c1e9a7b6d5c4e3f2a1b0c9d8e7f6a5b4c3d2e"
+
+	tests := []struct {
+		name    string
+		opts    *workspacev1alpha1.GitSource
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "default branch",
+			want: "git clone -q 'https://example.com/m.git' workspace",
+		},
+		{
+			name: "short commit",
+			opts: &workspacev1alpha1.GitSource{Commit: sha[:7]},
+			want: "git -C workspace checkout -q " + sha[:7],
+		},
+		{
+			name: "full commit with depth",
+			opts: &workspacev1alpha1.GitSource{Commit: sha, Depth: int32Ptr(1)},
+			want: "git -C workspace fetch -q --depth 1 origin " + sha,
+		},
+		{
+			name:    "short commit with depth",
+			opts:    &workspacev1alpha1.GitSource{Commit: sha[:12], Depth: int32Ptr(1)},
+			wantErr: true,
+		},
+		{
+			name:    "invalid commit",
+			opts:    &workspacev1alpha1.GitSource{Commit: "HEAD; id"},
+			wantErr: true,
+		},
+		{
+			name:    "ref as flag",
+			opts:    &workspacev1alpha1.GitSource{Ref: "--upload-pack=id"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := gitCloneCommand("https://example.com/m.git", tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("gitCloneCommand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("gitCloneCommand() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
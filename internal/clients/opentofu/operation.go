@@ -0,0 +1,122 @@
+package opentofu
+
+import (
+	"fmt"
+	"strings"
+
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+// annotationKeyOperation is the annotation of a runner Job describing the
+// Operation it runs, if any.
+const annotationKeyOperation = "opentofu.krateo.io/operation"
+
+// An Operation is a one-shot apply limited to, or replacing, some resource
+// addresses.
+type Operation struct {
+	Targets  []string
+	Replaces []string
+}
+
+// GetOperation returns the Operation requested with annotations on a
+// Workspace, or nil when there is none.
+func GetOperation(cr workspacev1alpha1.Workspace) *Operation {
+	op := Operation{
+		Targets:  splitAddresses(cr.GetAnnotations()[workspacev1alpha1.AnnotationKeyTarget]),
+		Replaces: splitAddresses(cr.GetAnnotations()[workspacev1alpha1.AnnotationKeyReplace]),
+	}
+	if len(op.Targets) == 0 && len(op.Replaces) == 0 {
+		return nil
+	}
+	return &op
+}
+
+// ClearOperation removes the annotations requesting an Operation.
+func ClearOperation(cr *workspacev1alpha1.Workspace) {
+	annotations := cr.GetAnnotations()
+	delete(annotations, workspacev1alpha1.AnnotationKeyTarget)
+	delete(annotations, workspacev1alpha1.AnnotationKeyReplace)
+	cr.SetAnnotations(annotations)
+}
+
+// Args returns the -target and -replace flags of tofu plan and apply.
+func (o Operation) Args() []string {
+	args := []string{}
+	for _, t := range o.Targets {
+		args = append(args, fmt.Sprintf("-target=%s", t))
+	}
+	for _, r := range o.Replaces {
+		args = append(args, fmt.Sprintf("-replace=%s", r))
+	}
+	return args
+}
+
+func (o Operation) String() string {
+	parts := []string{}
+	if len(o.Targets) > 0 {
+		parts = append(parts, "target="+strings.Join(o.Targets, ","))
+	}
+	if len(o.Replaces) > 0 {
+		parts = append(parts, "replace="+strings.Join(o.Replaces, ","))
+	}
+	return strings.Join(parts, " ")
+}
+
+// JobOperation returns the description of the Operation run by a Job, or
+// an empty string for regular runs.
+func JobOperation(job *batchv1.Job) string {
+	return job.GetAnnotations()[annotationKeyOperation]
+}
+
+// splitAddresses returns the addresses of a comma separated list. Commas
+// within index brackets or quotes, as in aws_instance.web["a,b"], are part
+// of the address.
+func splitAddresses(s string) []string {
+	addresses := []string{}
+	add := func(a string) {
+		if a = strings.TrimSpace(a); a != "" {
+			addresses = append(addresses, a)
+		}
+	}
+
+	start, depth := 0, 0
+	quoted, escaped := false, false
+	for i, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == '[':
+			depth++
+		case r == ']' && depth > 0:
+			depth--
+		case r == ',' && depth == 0:
+			add(s[start:i])
+			start = i + 1
+		}
+	}
+	add(s[start:])
+
+	return addresses
+}
+
+// A RunOption configures a Run.
+type RunOption func(*runOptions)
+
+type runOptions struct {
+	operation *Operation
+	defaults  RunnerDefaults
+}
+
+// WithOperation makes an InitPlan plan, and an InitApply apply, the given
+// Operation.
+func WithOperation(op Operation) RunOption {
+	return func(o *runOptions) {
+		o.operation = &op
+	}
+}
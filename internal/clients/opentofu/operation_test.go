@@ -0,0 +1,28 @@
+package opentofu
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitAddresses(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{in: "", want: []string{}},
+		{in: "aws_instance.web", want: []string{"aws_instance.web"}},
+		{in: " aws_instance.web , aws_instance.db ,", want: []string{"aws_instance.web", "aws_instance.db"}},
+		{in: `aws_instance.x["a,b"],aws_instance.y`, want: []string{`aws_instance.x["a,b"]`, "aws_instance.y"}},
+		{in: `module.m["a,b"].aws_instance.x[0]`, want: []string{`module.m["a,b"].aws_instance.x[0]`}},
+		{in: `aws_instance.x["a\"],b"],c`, want: []string{`aws_instance.x["a\"],b"]`, "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := splitAddresses(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitAddresses(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -11,13 +11,13 @@ import (
 	retry "github.com/avast/retry-go/v4"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 
+	connectorv1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/tfconnector/v1alpha1"
 	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
 	"github.com/krateoplatformops/opentofu-provider/internal/controllers/resolvers"
 	"github.com/pkg/errors"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	clientgo "k8s.io/client-go/kubernetes"
@@ -33,11 +33,6 @@ const (
 	InitPlan    Action = "init-plan"
 )
 
-const (
-	opentofuImage = "ghcr.io/opentofu/opentofu:latest"
-	gitImage      = "alpine/git:latest"
-)
-
 func int32Ptr(i int32) *int32 { return &i }
 
 type JobRunner struct {
@@ -45,7 +40,7 @@ type JobRunner struct {
 	Pod      corev1.Pod
 }
 
-func (r *JobRunner) generatePVC() *corev1.PersistentVolumeClaim {
+func (r *JobRunner) generatePVC(v connectorv1alpha1.WorkingVolume) *corev1.PersistentVolumeClaim {
 	// Create a new PVC
 	pvc := &corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
@@ -58,9 +53,10 @@ func (r *JobRunner) generatePVC() *corev1.PersistentVolumeClaim {
 			},
 			Resources: corev1.VolumeResourceRequirements{
 				Requests: corev1.ResourceList{
-					corev1.ResourceStorage: resource.MustParse("1Gi"),
+					corev1.ResourceStorage: storageRequest(v.Size, defaultWorkingVolumeSize),
 				},
 			},
+			StorageClassName: v.StorageClassName,
 		},
 	}
 	return pvc
@@ -147,28 +143,114 @@ func (r *JobRunner) generateJob() *batchv1.Job {
 
 }
 
-func (a Action) GetCMDs() []string {
+// CommandOptions are the additional arguments of the OpenTofu commands run
+// for an Action.
+type CommandOptions struct {
+	// Workspace is the OpenTofu workspace selected, and created if missing,
+	// before running plan, apply or destroy. When empty the default
+	// workspace is used and no workspace command is run, so that backends
+	// without workspaces are supported.
+	Workspace string
+
+	// BackendArgs are the -backend-config flags of init.
+	BackendArgs []string
+
+	// VarArgs are the -var-file and -var flags of plan, apply and destroy.
+	VarArgs []string
+
+	// OperationArgs are the -target and -replace flags of plan and apply.
+	OperationArgs []string
+
+	// Generation of the Workspace. When set, plan saves the plan and apply
+	// applies it, see planFileCMD and savesPlans.
+	Generation int64
+
+	// RequireSavedPlan makes apply fail, rather than plan again, when the
+	// saved plan is missing or stale, see savedPlanApplyCMD.
+	RequireSavedPlan bool
+
+	// Arguments set by users, appended to the respective commands.
+	InitArgs    []string
+	PlanArgs    []string
+	ApplyArgs   []string
+	DestroyArgs []string
+}
+
+func (a Action) GetCMDs(opts CommandOptions) []string {
+	vars := shellArgs(opts.VarArgs)
+	initCMDs := []string{
+		"tofu init -no-color -input=false" + shellArgs(opts.BackendArgs) + shellArgs(opts.InitArgs),
+	}
+	if opts.Workspace != "" {
+		initCMDs = append(initCMDs,
+			"tofu workspace select -no-color -or-create=true "+shellQuote(opts.Workspace),
+		)
+	}
+
 	switch a {
 	case InitApply:
-		return []string{
-			"tofu init -no-color -input=false",
-			"tofu apply -no-color -auto-approve -input=false",
+		apply := "tofu apply -no-color -auto-approve -input=false" + vars + shellArgs(opts.OperationArgs) + shellArgs(opts.ApplyArgs)
+		if opts.Generation == 0 {
+			return append(initCMDs, apply, outputsCMD)
 		}
+		_, applyArgs := splitApplyArgs(opts.ApplyArgs)
+		return append(initCMDs,
+			planFileCMD(opts.Generation),
+			savedPlanApplyCMD(apply, applyArgs, opts.RequireSavedPlan),
+			`rm -f "$PLAN"`,
+			outputsCMD,
+		)
 	case InitDestroy:
-		return []string{
-			"tofu init -no-color -input=false",
-			"tofu destroy -no-color -auto-approve -input=false",
+		cmds := append(initCMDs,
+			"tofu destroy -no-color -auto-approve -input=false"+vars+shellArgs(opts.DestroyArgs),
+		)
+		// The default workspace cannot be deleted; any other is left empty.
+		// The workspaces of cloud blocks and of the remote backend belong to
+		// the remote platform, which may have no default workspace and may
+		// not allow deleting them, so they are kept.
+		if opts.Workspace != "" && opts.Workspace != defaultWorkspace {
+			cmds = append(cmds, fmt.Sprintf("{ grep -qsE '%s' .terraform/terraform.tfstate || { tofu workspace select -no-color %s && tofu workspace delete -no-color %s; }; }",
+				remoteWorkspacesBackend, defaultWorkspace, shellQuote(opts.Workspace)))
 		}
+		return cmds
 	case InitPlan:
-		return []string{
-			"tofu init -no-color -input=false",
-			"tofu plan -no-color -input=false",
+		plan := "tofu plan -no-color -input=false" + vars + shellArgs(opts.OperationArgs) + shellArgs(opts.PlanArgs)
+		if opts.Generation == 0 {
+			// The plan is only kept to report its changes.
+			return append(initCMDs,
+				fmt.Sprintf("PLAN=%s", unsavedPlanFile),
+				detailedPlanCMD(plan+` -out="$PLAN"`),
+				showPlanCMD,
+			)
 		}
+		// The saved plan is applied as is, so it is planned with the flags of
+		// apply that change what is planned. Only the latest plan is kept.
+		planningArgs, _ := splitApplyArgs(opts.ApplyArgs)
+		plan += shellArgs(planningArgs)
+		return append(initCMDs,
+			planFileCMD(opts.Generation),
+			fmt.Sprintf("rm -f %s/*.tfplan", plansPath),
+			detailedPlanCMD(plan+` -out="$PLAN"`),
+			showPlanCMD,
+		)
 	default:
 		return []string{}
 	}
 }
 
+// defaultWorkspace is the OpenTofu workspace that always exists.
+const defaultWorkspace = "default"
+
+// remoteWorkspacesBackend matches the backend recorded by tofu init when it
+// is a cloud block or the remote backend.
+const remoteWorkspacesBackend = `"type": *"(cloud|remote)"`
+
+// WorkspaceName returns the name of the OpenTofu workspace of a Workspace,
+// empty when the default workspace is used.
+func WorkspaceName(cr workspacev1alpha1.Workspace) string {
+	return cr.GetAnnotations()[workspacev1alpha1.AnnotationKeyExternalName]
+}
+
 func (a Action) String() string {
 	return string(a)
 }
@@ -199,28 +281,12 @@ func ClassifyPodErr(str string) error {
 	return errors.New("unknown error")
 }
 
-// ClassifyPlanPodLog returns true if the workspace is up to date.
-func ClassifyPlanPodLog(str string) bool {
-	lines := bytes.Split([]byte(str), []byte("\n"))
-	if m := tfPlan.FindSubmatch([]byte(str)); len(lines) > 0 && len(m) > 1 {
-		return false
-	}
-	return true
-}
-
-func addOwnerRef(ctx context.Context, kube client.Client, sa *corev1.ServiceAccount, role *rbacv1.Role, roleBinding *rbacv1.RoleBinding, pvc *corev1.PersistentVolumeClaim, owRef metav1.OwnerReference) error {
-	sa.OwnerReferences = append(sa.OwnerReferences, owRef)
-	role.OwnerReferences = append(role.OwnerReferences, owRef)
-	roleBinding.OwnerReferences = append(roleBinding.OwnerReferences, owRef)
-
-	if err := kube.Update(ctx, sa); err != nil {
-		return fmt.Errorf("failed to update service account: %w", err)
-	}
-	if err := kube.Update(ctx, role); err != nil {
-		return fmt.Errorf("failed to update role: %w", err)
-	}
-	if err := kube.Update(ctx, roleBinding); err != nil {
-		return fmt.Errorf("failed to update role binding: %w", err)
+func addOwnerRef(ctx context.Context, kube client.Client, owRef metav1.OwnerReference, objs ...client.Object) error {
+	for _, obj := range objs {
+		obj.SetOwnerReferences(append(obj.GetOwnerReferences(), owRef))
+		if err := kube.Update(ctx, obj); err != nil {
+			return fmt.Errorf("failed to update %s: %w", obj.GetName(), err)
+		}
 	}
 	return nil
 }
@@ -240,6 +306,22 @@ func (job *JobInfo) GetSuccededPod() *corev1.Pod {
 	return nil
 }
 
+// GetCommit returns the SHA of the module commit checked out for the pod
+// that succeeded, as reported by its fetcher init container; it is empty
+// for modules that are not cloned from git.
+func (job *JobInfo) GetCommit() string {
+	pod := job.GetSuccededPod()
+	if pod == nil {
+		return ""
+	}
+	for _, status := range pod.Status.InitContainerStatuses {
+		if status.State.Terminated != nil {
+			return strings.TrimSpace(status.State.Terminated.Message)
+		}
+	}
+	return ""
+}
+
 func GetJobInfo(ctx context.Context, kube client.Client, jobname, namespace string) (*JobInfo, error) {
 	restconfig, err := ctrl.GetConfig()
 	if err != nil {
@@ -264,7 +346,8 @@ func GetJobInfo(ctx context.Context, kube client.Client, jobname, namespace stri
 
 	for _, pod := range pods.Items {
 		// if strings.Contains(pod.GetName(), jobname) {
-		req := clientraw.CoreV1().Pods(namespace).GetLogs(pod.GetName(), &corev1.PodLogOptions{})
+		// The runner container is named after the job.
+		req := clientraw.CoreV1().Pods(namespace).GetLogs(pod.GetName(), &corev1.PodLogOptions{Container: jobname})
 		podLogs, err := req.Stream(ctx)
 		if err != nil {
 			errsbuf = append(errsbuf, fmt.Sprintf("%s: %s", pod.GetName(), err.Error()))
@@ -311,26 +394,93 @@ func JobNamer(meta metav1.ObjectMeta, action Action) string {
 	return fmt.Sprintf("%s-opentofu-%s", meta.GetName(), action.String())
 }
 
-func Run(ctx context.Context, kube client.Client, cr workspacev1alpha1.Workspace, action Action) error {
+func Run(ctx context.Context, kube client.Client, cr workspacev1alpha1.Workspace, action Action, runOpts ...RunOption) error {
+	ro := runOptions{}
+	for _, o := range runOpts {
+		o(&ro)
+	}
+
 	cfg, err := resolvers.ResolveTFConnector(ctx, kube, cr.Spec.TFConnectorRef)
 	if err != nil {
 		return fmt.Errorf("failed to resolve TFConnector: %w", err)
 	}
 
-	var envs []corev1.EnvFromSource
-	envs = append(envs, cfg.Spec.EnvVars...)
-	envs = append(envs, cfg.Spec.ProvidersCredentials.EnvVars...)
+	// Later sources take precedence, hence the Workspace ones come last.
+	envs := append(connectorEnvFrom(cfg), cr.Spec.Workspace.EnvFrom...)
 
 	var initEnvs []corev1.EnvFromSource
 	if cfg.Spec.GitCredentials != nil {
 		initEnvs = append(initEnvs, *cfg.Spec.GitCredentials)
 	}
 
-	cmds := strings.Join(action.GetCMDs(), " && ")
+	entrypoint, err := ValidateEntrypoint(cr.Spec.Workspace.Entrypoint)
+	if err != nil {
+		return err
+	}
 
-	// fmt.Println("Cmds: ", cmds)
+	files := newRunnerFiles()
+
+	if err := resolveConnectorConfiguration(ctx, kube, cfg, files); err != nil {
+		return err
+	}
+
+	if err := resolveCredentialsFiles(ctx, kube, cfg, files); err != nil {
+		return err
+	}
+
+	if err := resolveBackendCredentials(ctx, kube, cfg, files); err != nil {
+		return err
+	}
+
+	backendArgs, err := resolveBackendArgs(ctx, kube, cfg, cr, files)
+	if err != nil {
+		return err
+	}
+
+	varArgs, err := resolveVarArgs(ctx, kube, cr, files)
+	if err != nil {
+		return err
+	}
 
-	cloneCommand := fmt.Sprintf("git clone -c credential.helper='!f() { echo username=author; echo \"password=$GIT_CREDENTIALS\"; };f' %s workspace", cr.Spec.Workspace.Module)
+	opts := CommandOptions{
+		Workspace:   WorkspaceName(cr),
+		BackendArgs: backendArgs,
+		VarArgs:     varArgs,
+		InitArgs:    cr.Spec.Workspace.InitArgs,
+		PlanArgs:    cr.Spec.Workspace.PlanArgs,
+		ApplyArgs:   cr.Spec.Workspace.ApplyArgs,
+		DestroyArgs: cr.Spec.Workspace.DestroyArgs,
+	}
+	for command, args := range map[string][]string{
+		"init":    opts.InitArgs,
+		"plan":    opts.PlanArgs,
+		"apply":   opts.ApplyArgs,
+		"destroy": opts.DestroyArgs,
+	} {
+		if err := ValidateArgs(command, args); err != nil {
+			return err
+		}
+	}
+	if ro.operation != nil {
+		opts.OperationArgs = ro.operation.Args()
+	}
+	if (action == InitPlan || action == InitApply) && savesPlans(cr) {
+		opts.Generation = cr.GetGeneration()
+		// Plans that are approved, or checked by guardrails, are applied as
+		// they are or not at all.
+		opts.RequireSavedPlan = cr.Spec.Workspace.RequireApproval || cr.Spec.Workspace.Guardrails != nil
+	}
+
+	// Changing directory rather than setting the container working directory
+	// makes a missing entrypoint fail loudly instead of running in an empty one.
+	cmds := []string{versionCMD, fmt.Sprintf("cd %s", shellQuote(entrypoint))}
+	cmds = append(cmds, files.CopyCMDs()...)
+	if cfg.Spec.Backend == nil {
+		cmds = append(cmds, defaultBackendCMD(cr, files))
+	}
+	cmds = append(cmds, action.GetCMDs(opts)...)
+
+	// fmt.Println("Cmds: ", cmds)
 
 	name := JobNamer(cr.ObjectMeta, action)
 	runner := JobRunner{
@@ -340,7 +490,11 @@ func Run(ctx context.Context, kube client.Client, cr workspacev1alpha1.Workspace
 		},
 	}
 
-	pvc := runner.generatePVC()
+	workingVolume := resolveWorkingVolume(cr, cfg)
+	if opts.RequireSavedPlan && workingVolume.Type == connectorv1alpha1.WorkingVolumeEmptyDir {
+		return fmt.Errorf("workspaces requiring approval or with guardrails need a working volume of type %s to keep saved plans", connectorv1alpha1.WorkingVolumeEphemeral)
+	}
+	volume := runner.generateWorkingVolume(workingVolume)
 
 	// Need to set owner reference for the PVC and for service account, role and role binding
 	sa := runner.generateServiceAccount()
@@ -356,61 +510,117 @@ func Run(ctx context.Context, kube client.Client, cr workspacev1alpha1.Workspace
 		return fmt.Errorf("failed to create role binding: %w", err)
 	}
 
+	images := resolveImages(cr, cfg, ro.defaults)
+
+	volumeMount := corev1.VolumeMount{
+		Name:      volume.Name,
+		MountPath: volumePath,
+	}
+
+	fetcher, err := runner.fetchModule(ctx, kube, cr, images, volumeMount, initEnvs, envs)
+	if err != nil {
+		return fmt.Errorf("failed to prepare module fetcher: %w", err)
+	}
+
+	volumeMounts := []corev1.VolumeMount{volumeMount}
+	volumes := fetcher.Volumes
+
+	if opts.Generation != 0 {
+		// Plans saved to an emptyDir volume do not outlive the plan Job, so
+		// the apply plans again.
+		source := corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}
+		if workingVolume.Type != connectorv1alpha1.WorkingVolumeEmptyDir {
+			plans := generatePlansPVC(cr, workingVolume.StorageClassName)
+			if err := InstallPVC(ctx, kube, plans); err != nil {
+				return fmt.Errorf("failed to create saved plans volume: %w", err)
+			}
+			source = corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: plans.GetName()},
+			}
+		}
+
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      plansVolume,
+			MountPath: plansPath,
+		})
+		volumes = append(volumes, corev1.Volume{
+			Name:         plansVolume,
+			VolumeSource: source,
+		})
+	}
+	report := runner.generateReportSecret()
+	if err := InstallSecret(ctx, kube, report); err != nil {
+		return fmt.Errorf("failed to create report secret: %w", err)
+	}
+	volumeMounts = append(volumeMounts, corev1.VolumeMount{
+		Name:      reportVolume,
+		MountPath: reportPath,
+	})
+	volumes = append(volumes, corev1.Volume{
+		Name:         reportVolume,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	})
+
+	owned := append([]client.Object{sa, role, roleBinding, report}, fetcher.Objects...)
+
+	var env []corev1.EnvVar
+	if !files.Empty() {
+		sec := runner.generateFilesSecret(files)
+		if err := InstallSecret(ctx, kube, sec); err != nil {
+			return fmt.Errorf("failed to create runner files secret: %w", err)
+		}
+		owned = append(owned, sec)
+		env = files.EnvVars(sec.GetName())
+
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      runnerFilesVolume,
+			MountPath: runnerFilesPath,
+			ReadOnly:  true,
+		})
+		volumes = append(volumes, corev1.Volume{
+			Name: runnerFilesVolume,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: sec.GetName()},
+			},
+		})
+	}
+
+	var annotations map[string]string
+	if ro.operation != nil {
+		annotations = map[string]string{annotationKeyOperation: ro.operation.String()}
+	}
+
 	runner.Pod = corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: cr.ObjectMeta.Namespace,
+			Name:        name,
+			Namespace:   cr.ObjectMeta.Namespace,
+			Annotations: annotations,
 		},
 		Spec: corev1.PodSpec{
-			Containers: []corev1.Container{
-				{
-					Name:       name,
-					Image:      opentofuImage,
-					WorkingDir: "/mnt/workspace",
-					Command:    []string{"sh", "-c"},
-					Args:       []string{cmds},
-					VolumeMounts: []corev1.VolumeMount{
-						{
-							Name:      pvc.GetName(),
-							MountPath: "/mnt",
-						},
-					},
-					EnvFrom: envs,
-				},
-			},
-			ServiceAccountName: sa.GetName(),
+			// OpenTofu runs once the module is fetched, and the reporter once
+			// OpenTofu succeeded.
 			InitContainers: []corev1.Container{
+				fetcher.Container,
 				{
-					Name:       fmt.Sprintf("%s-init", name),
-					Image:      gitImage,
-					EnvFrom:    initEnvs,
-					WorkingDir: "/mnt",
-					VolumeMounts: []corev1.VolumeMount{
-						{
-							Name:      pvc.GetName(),
-							MountPath: "/mnt",
-						},
-					},
-					Command: []string{"sh", "-c"},
-					Args:    []string{cloneCommand},
-				},
-			},
-			Volumes: []corev1.Volume{
-				{
-					Name: pvc.GetName(),
-					VolumeSource: corev1.VolumeSource{
-						Ephemeral: &corev1.EphemeralVolumeSource{
-							VolumeClaimTemplate: &corev1.PersistentVolumeClaimTemplate{
-								Spec: pvc.Spec,
-							},
-						},
-					},
+					Name:         name,
+					Image:        images.OpenTofu,
+					WorkingDir:   workspacePath,
+					Command:      []string{"sh", "-c"},
+					Args:         []string{strings.Join(cmds, " && ")},
+					VolumeMounts: volumeMounts,
+					Env:          mergeEnv(env, cr.Spec.Workspace.Env),
+					EnvFrom:      envs,
 				},
 			},
+			Containers:         []corev1.Container{runner.reporter(images.Reporter)},
+			ServiceAccountName: sa.GetName(),
+			ImagePullSecrets:   images.PullSecrets,
+			Volumes:            append([]corev1.Volume{volume}, volumes...),
 		},
 	}
 
 	job := runner.generateJob()
+	applyPodTemplate(job, mergePodTemplates(cfg.Spec.PodTemplate, cr.Spec.Workspace.PodTemplate))
 
 	// bjob, err := yaml.Marshal(job)
 	// fmt.Println(string(bjob))
@@ -428,7 +638,7 @@ func Run(ctx context.Context, kube client.Client, cr workspacev1alpha1.Workspace
 		Name:       job.GetName(),
 		UID:        job.GetUID(),
 	}
-	if err := addOwnerRef(ctx, kube, sa, role, roleBinding, pvc, owRef); err != nil {
+	if err := addOwnerRef(ctx, kube, owRef, owned...); err != nil {
 		return fmt.Errorf("failed to add owner reference: %w", err)
 	}
 
@@ -0,0 +1,109 @@
+package opentofu
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGetCMDsDestroy(t *testing.T) {
+	const (
+		initCMD = "tofu init -no-color -input=false"
+		destroy = "tofu destroy -no-color -auto-approve -input=false"
+	)
+
+	tests := []struct {
+		name string
+		opts CommandOptions
+		want []string
+	}{
+		{
+			name: "default workspace",
+			want: []string{initCMD, destroy},
+		},
+		{
+			name: "default workspace named",
+			opts: CommandOptions{Workspace: "default"},
+			want: []string{initCMD, "tofu workspace select -no-color -or-create=true 'default'", destroy},
+		},
+		{
+			name: "workspace",
+			opts: CommandOptions{Workspace: "prod", DestroyArgs: []string{"-parallelism=5"}},
+			want: []string{
+				initCMD,
+				"tofu workspace select -no-color -or-create=true 'prod'",
+				destroy + " '-parallelism=5'",
+				`{ grep -qsE '"type": *"(cloud|remote)"' .terraform/terraform.tfstate || { tofu workspace select -no-color default && tofu workspace delete -no-color 'prod'; }; }`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InitDestroy.GetCMDs(tt.opts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetCMDs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoteWorkspacesBackend(t *testing.T) {
+	if _, err := exec.LookPath("grep"); err != nil {
+		t.Skip("grep not found")
+	}
+
+	tests := []struct {
+		name  string
+		state string
+		want  bool
+	}{
+		{name: "cloud", state: "{\n\t\"backend\": {\n\t\t\"type\": \"cloud\",\n\t\t\"config\": {}\n\t}\n}", want: true},
+		{name: "remote", state: "{\n\t\"backend\": {\n\t\t\"type\": \"remote\",\n\t\t\"config\": {}\n\t}\n}", want: true},
+		{name: "s3", state: "{\n\t\"backend\": {\n\t\t\"type\": \"s3\",\n\t\t\"config\": {\"key\": \"remote\"}\n\t}\n}", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "terraform.tfstate")
+			if err := os.WriteFile(path, []byte(tt.state), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			got := exec.Command("grep", "-qsE", remoteWorkspacesBackend, path).Run() == nil
+			if got != tt.want {
+				t.Errorf("grep %s = %v, want %v", remoteWorkspacesBackend, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetCMDsPlan(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     CommandOptions
+		wantPlan string
+	}{
+		{
+			name:     "unsaved plan",
+			wantPlan: "PLAN=/tmp/krateo.tfplan",
+		},
+		{
+			name:     "saved plan",
+			opts:     CommandOptions{Generation: 3},
+			wantPlan: planFileCMD(3),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := InitPlan.GetCMDs(tt.opts)
+			if got[1] != tt.wantPlan {
+				t.Errorf("GetCMDs()[1] = %q, want %q", got[1], tt.wantPlan)
+			}
+			if got[len(got)-1] != showPlanCMD {
+				t.Errorf("GetCMDs() = %q, want the plan to be shown last", got)
+			}
+		})
+	}
+}
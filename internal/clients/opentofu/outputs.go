@@ -0,0 +1,33 @@
+package opentofu
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+)
+
+// outputsCMD writes the outputs of the root module to the report volume,
+// since they may be sensitive.
+var outputsCMD = fmt.Sprintf("tofu output -no-color -json > %s", path.Join(reportPath, outputsFile))
+
+// An Output of the root module, as reported by tofu output -json.
+type Output struct {
+	Sensitive bool            `json:"sensitive"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// String returns the value of a string output as is, and the JSON encoding
+// of the value for any other type, e.g. lists and maps.
+func (o Output) String() string {
+	var s string
+	if err := json.Unmarshal(o.Value, &s); err == nil {
+		return s
+	}
+
+	buf := new(bytes.Buffer)
+	if err := json.Compact(buf, o.Value); err != nil {
+		return string(o.Value)
+	}
+	return buf.String()
+}
@@ -0,0 +1,174 @@
+package opentofu
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// detailedPlanCMD runs plan with -detailed-exitcode, records its exit code
+// in the termination message of the runner container and only fails on
+// errors: 0 means no changes, 1 an error and 2 changes.
+func detailedPlanCMD(plan string) string {
+	return fmt.Sprintf("{ %s -detailed-exitcode; rc=$?; echo $rc > /dev/termination-log; [ $rc -ne 1 ]; }", plan)
+}
+
+// showPlanCMD writes the saved plan, when it has changes, to the report
+// volume. It holds the values of the resources, sensitive ones included.
+var showPlanCMD = fmt.Sprintf(`{ [ $rc -ne 2 ] || tofu show -no-color -json "$PLAN" > %s; }`, path.Join(reportPath, planFile))
+
+// PlanHasChanges returns whether the plan run by the pod that succeeded
+// found changes, according to the exit code recorded by detailedPlanCMD.
+func PlanHasChanges(pod *corev1.Pod) (bool, error) {
+	for _, status := range pod.Status.InitContainerStatuses {
+		// The runner container is named after the job.
+		if status.Name != pod.GetLabels()["job-name"] || status.State.Terminated == nil {
+			continue
+		}
+		switch code := strings.TrimSpace(status.State.Terminated.Message); code {
+		case "0":
+			return false, nil
+		case "2":
+			return true, nil
+		default:
+			return false, fmt.Errorf("unexpected plan exit code %q", code)
+		}
+	}
+	return false, fmt.Errorf("no plan exit code found")
+}
+
+// planJSON is the part of the output of tofu show -json the controller
+// relies on.
+type planJSON struct {
+	ResourceChanges []struct {
+		Address      string `json:"address"`
+		Type         string `json:"type"`
+		ProviderName string `json:"provider_name"`
+		ActionReason string `json:"action_reason"`
+		Change       struct {
+			Actions         []string        `json:"actions"`
+			Before          json.RawMessage `json:"before"`
+			After           json.RawMessage `json:"after"`
+			AfterUnknown    json.RawMessage `json:"after_unknown"`
+			BeforeSensitive json.RawMessage `json:"before_sensitive"`
+			AfterSensitive  json.RawMessage `json:"after_sensitive"`
+			ReplacePaths    [][]interface{} `json:"replace_paths"`
+		} `json:"change"`
+	} `json:"resource_changes"`
+}
+
+// parsePlanJSON returns the saved plan reported by an InitPlan job.
+func parsePlanJSON(data []byte) (*planJSON, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("no plan reported")
+	}
+
+	plan := planJSON{}
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+	return &plan, nil
+}
+
+// ParsePlannedChanges counts the resources changed by the saved plan
+// reported by an InitPlan job.
+func ParsePlannedChanges(data []byte) (*workspacev1alpha1.PlannedChanges, error) {
+	plan, err := parsePlanJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := &workspacev1alpha1.PlannedChanges{}
+	for _, rc := range plan.ResourceChanges {
+		for _, action := range rc.Change.Actions {
+			switch action {
+			case "create":
+				changes.Add++
+			case "update":
+				changes.Change++
+			case "delete":
+				changes.Destroy++
+			}
+		}
+	}
+	return changes, nil
+}
+
+// PlanSummary returns the summary of planned changes, in the words of tofu
+// plan.
+func PlanSummary(changes *workspacev1alpha1.PlannedChanges) string {
+	return fmt.Sprintf("Plan: %d to add, %d to change, %d to destroy.", changes.Add, changes.Change, changes.Destroy)
+}
+
+// planChangesMarker starts the description of the changes in the output
+// of tofu plan.
+const planChangesMarker = "OpenTofu will perform the following actions:"
+
+// PlanText returns the changes described in the logs of an InitPlan job,
+// or the whole logs when they cannot be told apart.
+func PlanText(logs string) string {
+	begin := strings.Index(logs, planChangesMarker)
+	if begin < 0 {
+		return logs
+	}
+
+	text := logs[begin:]
+	if loc := tfPlan.FindStringIndex(text); loc != nil {
+		text = text[:loc[1]]
+	}
+	return text
+}
+
+// PlanID identifies the changes planned for a generation of a Workspace,
+// so that an approval does not apply to a plan with different inputs or
+// changes. digest is the one of the changes of the plan, see planDigest.
+func PlanID(generation int64, digest string) string {
+	sum := sha256.Sum256([]byte(strconv.FormatInt(generation, 10) + "\n" + digest))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// planDigest returns the digest of the resource and output changes of a
+// saved plan. Unlike the rest of the output of tofu show -json, e.g. its
+// timestamp, they are the same for every plan of the same changes.
+func planDigest(data []byte) (string, error) {
+	plan := struct {
+		ResourceChanges json.RawMessage `json:"resource_changes"`
+		OutputChanges   json.RawMessage `json:"output_changes"`
+	}{}
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return "", fmt.Errorf("failed to parse plan: %w", err)
+	}
+
+	h := sha256.New()
+	for _, raw := range []json.RawMessage{plan.ResourceChanges, plan.OutputChanges} {
+		canonical, err := canonicalJSON(raw)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse plan: %w", err)
+		}
+		h.Write(canonical)
+		h.Write([]byte("\n"))
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// canonicalJSON returns raw with sorted object keys and no whitespace.
+func canonicalJSON(raw json.RawMessage) ([]byte, error) {
+	if len(raw) == 0 {
+		return []byte("null"), nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
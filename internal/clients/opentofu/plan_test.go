@@ -0,0 +1,189 @@
+package opentofu
+
+import (
+	"reflect"
+	"testing"
+
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func terminated(name, message string) corev1.ContainerStatus {
+	return corev1.ContainerStatus{
+		Name: name,
+		State: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{Message: message},
+		},
+	}
+}
+
+func TestPlanHasChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []corev1.ContainerStatus
+		want     bool
+		wantErr  bool
+	}{
+		{
+			name:     "no changes",
+			statuses: []corev1.ContainerStatus{terminated("ws-opentofu-init-plan-init", "abc123"), terminated("ws-opentofu-init-plan", "0\n")},
+			want:     false,
+		},
+		{
+			name:     "changes",
+			statuses: []corev1.ContainerStatus{terminated("ws-opentofu-init-plan", "2\n")},
+			want:     true,
+		},
+		{
+			name:     "error exit code",
+			statuses: []corev1.ContainerStatus{terminated("ws-opentofu-init-plan", "1")},
+			wantErr:  true,
+		},
+		{
+			name:     "fetcher message is not an exit code",
+			statuses: []corev1.ContainerStatus{terminated("ws-opentofu-init-plan-init", "2")},
+			wantErr:  true,
+		},
+		{
+			name:     "runner not terminated",
+			statuses: []corev1.ContainerStatus{{Name: "ws-opentofu-init-plan"}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"job-name": "ws-opentofu-init-plan"}},
+				Status:     corev1.PodStatus{InitContainerStatuses: tt.statuses},
+			}
+			got, err := PlanHasChanges(pod)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PlanHasChanges() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("PlanHasChanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePlannedChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		plan    string
+		want    *workspacev1alpha1.PlannedChanges
+		wantErr bool
+	}{
+		{
+			name: "every action",
+			plan: `{"resource_changes": [
+				{"address": "a.new", "change": {"actions": ["create"]}},
+				{"address": "a.changed", "change": {"actions": ["update"]}},
+				{"address": "a.gone", "change": {"actions": ["delete"]}},
+				{"address": "a.replaced", "change": {"actions": ["delete", "create"]}},
+				{"address": "a.same", "change": {"actions": ["no-op"]}},
+				{"address": "data.a.read", "change": {"actions": ["read"]}}
+			]}`,
+			want: &workspacev1alpha1.PlannedChanges{Add: 2, Change: 1, Destroy: 2},
+		},
+		{
+			name: "no resource changes",
+			plan: `{"format_version": "1.2"}`,
+			want: &workspacev1alpha1.PlannedChanges{},
+		},
+		{
+			name:    "no plan",
+			plan:    "",
+			wantErr: true,
+		},
+		{
+			name:    "invalid plan",
+			plan:    `{"resource_changes": [`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePlannedChanges([]byte(tt.plan))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePlannedChanges() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePlannedChanges() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanID(t *testing.T) {
+	plan := `{"format_version": "1.2", "timestamp": "2024-01-01T00:00:00Z",
+		"resource_changes": [{"address": "a.b", "change": {"actions": ["create"], "after": {"x": 1, "y": 2}}}],
+		"output_changes": {"ip": {"actions": ["create"]}}}`
+
+	tests := []struct {
+		name       string
+		plan       string
+		generation int64
+		same       bool
+	}{
+		{
+			name:       "same plan",
+			plan:       plan,
+			generation: 1,
+			same:       true,
+		},
+		{
+			name: "other timestamp and formatting",
+			plan: `{"timestamp": "2024-06-30T12:34:56Z", "format_version": "1.2",
+				"output_changes": {"ip": {"actions": ["create"]}},
+				"resource_changes": [{"change": {"after": {"y": 2, "x": 1}, "actions": ["create"]}, "address": "a.b"}]}`,
+			generation: 1,
+			same:       true,
+		},
+		{
+			name:       "other generation",
+			plan:       plan,
+			generation: 2,
+			same:       false,
+		},
+		{
+			name: "other resource changes",
+			plan: `{"resource_changes": [{"address": "a.b", "change": {"actions": ["create"], "after": {"x": 1, "y": 3}}}],
+				"output_changes": {"ip": {"actions": ["create"]}}}`,
+			generation: 1,
+			same:       false,
+		},
+		{
+			name: "other output changes",
+			plan: `{"resource_changes": [{"address": "a.b", "change": {"actions": ["create"], "after": {"x": 1, "y": 2}}}],
+				"output_changes": {"ip": {"actions": ["update"]}}}`,
+			generation: 1,
+			same:       false,
+		},
+	}
+
+	digest, err := planDigest([]byte(plan))
+	if err != nil {
+		t.Fatalf("planDigest() error = %v", err)
+	}
+	want := PlanID(1, digest)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := planDigest([]byte(tt.plan))
+			if err != nil {
+				t.Fatalf("planDigest() error = %v", err)
+			}
+			got := PlanID(tt.generation, d)
+			if len(got) != 16 {
+				t.Errorf("PlanID() = %q, want 16 hex digits", got)
+			}
+			if (got == want) != tt.same {
+				t.Errorf("PlanID() = %q, base plan %q, want same = %v", got, want, tt.same)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package opentofu
+
+import (
+	"fmt"
+
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+	"github.com/krateoplatformops/provider-runtime/pkg/meta"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	plansVolume = "saved-plans"
+	plansPath   = "/opt/krateo/plans"
+)
+
+// unsavedPlanFile is where plans that are not saved are written, so that
+// their changes can be reported.
+const unsavedPlanFile = "/tmp/krateo.tfplan"
+
+// stalePlanError is the error tofu apply reports when the state changed
+// after the saved plan was created.
+const stalePlanError = "Saved plan is stale"
+
+// savesPlans returns whether the plans of a Workspace are saved, and its
+// applies run them as they are: when plans need approval or are checked by
+// guardrails, and when the management policy does not allow create, so that
+// the plan of a missing Workspace is reported rather than applied.
+func savesPlans(cr workspacev1alpha1.Workspace) bool {
+	return cr.Spec.Workspace.RequireApproval || cr.Spec.Workspace.Guardrails != nil ||
+		!meta.IsActionAllowed(&cr, meta.ActionCreate)
+}
+
+// planFileCMD sets PLAN to the path of the saved plan of a generation of
+// the Workspace and of the commit of the module, if known.
+func planFileCMD(generation int64) string {
+	return fmt.Sprintf(`PLAN="%s/%d-$(cat %s 2>/dev/null || echo none).tfplan"`, plansPath, generation, commitFile)
+}
+
+// savedPlanApplyCMD applies the saved plan, if any. When it is missing, or
+// stale, apply plans again, unless strict is set: then the apply fails, so
+// that the changes are planned, and checked, again before any apply.
+func savedPlanApplyCMD(apply string, applyArgs []string, strict bool) string {
+	saved := "tofu apply -no-color -input=false" + shellArgs(applyArgs) + ` "$PLAN"`
+	if strict {
+		return fmt.Sprintf(`if [ -f "$PLAN" ]; then %s; else echo 'Error: no saved plan found, planning again.' >&2 && false; fi`, saved)
+	}
+	return fmt.Sprintf(`if [ -f "$PLAN" ]; then { %s 2>/tmp/apply.err; rc=$?; cat /tmp/apply.err >&2; [ $rc -eq 0 ] || { grep -q '%s' /tmp/apply.err && echo 'The saved plan is stale, planning again.' && %s; }; }; else echo 'No saved plan found, planning again.' && %s; fi`,
+		saved, stalePlanError, apply, apply)
+}
+
+// generatePlansPVC returns the PVC keeping the saved plans of a Workspace
+// across runner Jobs. It is owned by the Workspace.
+func generatePlansPVC(cr workspacev1alpha1.Workspace, storageClassName *string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-opentofu-plans", cr.GetName()),
+			Namespace: cr.GetNamespace(),
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: workspacev1alpha1.SchemeGroupVersion.String(),
+					Kind:       workspacev1alpha1.WorkspaceKind,
+					Name:       cr.GetName(),
+					UID:        cr.GetUID(),
+				},
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{
+				corev1.ReadWriteOnce,
+			},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: resource.MustParse("100Mi"),
+				},
+			},
+			StorageClassName: storageClassName,
+		},
+	}
+}
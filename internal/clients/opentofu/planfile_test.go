@@ -0,0 +1,36 @@
+package opentofu
+
+import (
+	"testing"
+
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+	"github.com/krateoplatformops/provider-runtime/pkg/meta"
+)
+
+func TestSavesPlans(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		spec   workspacev1alpha1.WorkspaceParameters
+		want   bool
+	}{
+		{name: "ungated", want: false},
+		{name: "observe and create", policy: meta.ManagementPolicyObserveCreateUpdate, want: false},
+		{name: "require approval", spec: workspacev1alpha1.WorkspaceParameters{RequireApproval: true}, want: true},
+		{name: "guardrails", spec: workspacev1alpha1.WorkspaceParameters{Guardrails: &workspacev1alpha1.Guardrails{}}, want: true},
+		{name: "create not allowed", policy: meta.ManagementPolicyObserve, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := workspacev1alpha1.Workspace{}
+			cr.Spec.Workspace = tt.spec
+			if tt.policy != "" {
+				cr.SetAnnotations(map[string]string{meta.AnnotationKeyManagementPolicy: tt.policy})
+			}
+			if got := savesPlans(cr); got != tt.want {
+				t.Errorf("savesPlans() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
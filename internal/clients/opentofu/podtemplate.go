@@ -0,0 +1,123 @@
+package opentofu
+
+import (
+	connectorv1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/tfconnector/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// mergePodTemplates returns the pod template of the TFConnector patched by
+// the one of the Workspace. Maps are merged, the values of patch winning,
+// tolerations are appended and any other field set by patch is replaced.
+func mergePodTemplates(base, patch *connectorv1alpha1.PodTemplate) *connectorv1alpha1.PodTemplate {
+	if base == nil {
+		return patch
+	}
+	if patch == nil {
+		return base
+	}
+
+	merged := base.DeepCopy()
+	patch = patch.DeepCopy()
+
+	merged.Labels = mergeMaps(merged.Labels, patch.Labels)
+	merged.Annotations = mergeMaps(merged.Annotations, patch.Annotations)
+	merged.NodeSelector = mergeMaps(merged.NodeSelector, patch.NodeSelector)
+	merged.Tolerations = append(merged.Tolerations, patch.Tolerations...)
+
+	if patch.Resources != nil {
+		if merged.Resources == nil {
+			merged.Resources = &corev1.ResourceRequirements{}
+		}
+		merged.Resources.Limits = mergeResources(merged.Resources.Limits, patch.Resources.Limits)
+		merged.Resources.Requests = mergeResources(merged.Resources.Requests, patch.Resources.Requests)
+		if patch.Resources.Claims != nil {
+			merged.Resources.Claims = patch.Resources.Claims
+		}
+	}
+	if patch.Affinity != nil {
+		merged.Affinity = patch.Affinity
+	}
+	if patch.PriorityClassName != "" {
+		merged.PriorityClassName = patch.PriorityClassName
+	}
+	if patch.SecurityContext != nil {
+		merged.SecurityContext = patch.SecurityContext
+	}
+	if patch.ContainerSecurityContext != nil {
+		merged.ContainerSecurityContext = patch.ContainerSecurityContext
+	}
+
+	return merged
+}
+
+// applyPodTemplate merges a pod template into the pod template of a runner
+// Job. Resources only apply to the runner container, the security context
+// of containers to every container.
+func applyPodTemplate(job *batchv1.Job, t *connectorv1alpha1.PodTemplate) {
+	if t == nil {
+		return
+	}
+
+	tmpl := &job.Spec.Template
+	tmpl.Labels = mergeMaps(tmpl.Labels, t.Labels)
+	tmpl.Annotations = mergeMaps(tmpl.Annotations, t.Annotations)
+
+	spec := &tmpl.Spec
+	spec.NodeSelector = mergeMaps(spec.NodeSelector, t.NodeSelector)
+	spec.Tolerations = append(spec.Tolerations, t.Tolerations...)
+	if t.Affinity != nil {
+		spec.Affinity = t.Affinity
+	}
+	if t.PriorityClassName != "" {
+		spec.PriorityClassName = t.PriorityClassName
+	}
+	if t.SecurityContext != nil {
+		spec.SecurityContext = t.SecurityContext
+	}
+
+	if t.Resources != nil {
+		// The runner container is named after the job.
+		for i := range spec.InitContainers {
+			if spec.InitContainers[i].Name == job.GetName() {
+				spec.InitContainers[i].Resources = *t.Resources
+			}
+		}
+	}
+	if t.ContainerSecurityContext != nil {
+		for i := range spec.InitContainers {
+			spec.InitContainers[i].SecurityContext = t.ContainerSecurityContext.DeepCopy()
+		}
+		for i := range spec.Containers {
+			spec.Containers[i].SecurityContext = t.ContainerSecurityContext.DeepCopy()
+		}
+	}
+}
+
+func mergeMaps(base, patch map[string]string) map[string]string {
+	if len(patch) == 0 {
+		return base
+	}
+	merged := make(map[string]string, len(base)+len(patch))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range patch {
+		merged[k] = v
+	}
+	return merged
+}
+
+func mergeResources(base, patch corev1.ResourceList) corev1.ResourceList {
+	if len(patch) == 0 {
+		return base
+	}
+	merged := make(corev1.ResourceList, len(base)+len(patch))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range patch {
+		merged[k] = v
+	}
+	return merged
+}
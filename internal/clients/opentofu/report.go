@@ -0,0 +1,222 @@
+package opentofu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	retry "github.com/avast/retry-go/v4"
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The runner container writes what the controller needs to know, and may be
+// sensitive, to files of the report volume rather than to its logs. The
+// reporter container then stores them in the report Secret of the Job.
+const (
+	reportVolume = "report"
+	reportPath   = "/opt/krateo/report"
+
+	// reporterContainer is the name of the container running the reporter.
+	reporterContainer = "reporter"
+
+	// planFile holds the output of tofu show -json for the saved plan.
+	planFile = "plan.json"
+	// outputsFile holds the output of tofu output -json.
+	outputsFile = "outputs.json"
+)
+
+// Keys of the report Secret.
+const (
+	reportChangesKey    = "changes.json"
+	reportPlannedKey    = "planned.json"
+	reportPlanDigestKey = "planDigest"
+	reportOutputsKey    = "outputs.json"
+)
+
+// maxReportChangesSize keeps the report, and the ConfigMap the resource
+// changes are published to, below the size limit of Kubernetes objects.
+const maxReportChangesSize = 900 * 1024
+
+// ReportSecretName returns the name of the Secret holding the report of a
+// runner Job.
+func ReportSecretName(jobName string) string {
+	return fmt.Sprintf("%s-report", jobName)
+}
+
+// A Report holds what a runner Job found out.
+type Report struct {
+	// Changes of the resources of the saved plan, when it has changes.
+	// Sensitive values are redacted.
+	Changes []ResourceChange
+	// Planned counts the changes of the saved plan, when it has changes.
+	Planned *workspacev1alpha1.PlannedChanges
+	// PlanDigest is the digest of the changes of the saved plan, see
+	// PlanID.
+	PlanDigest string
+	// Outputs of the root module, after an apply.
+	Outputs map[string]Output
+}
+
+// BuildReport returns the data of the report Secret from the files written
+// to dir by the runner container. The saved plan is reduced to its redacted
+// resource changes, so that no sensitive value of the plan leaves the pod.
+func BuildReport(dir string) (map[string][]byte, error) {
+	data := map[string][]byte{}
+
+	plan, err := readReportFile(dir, planFile)
+	if err != nil {
+		return nil, err
+	}
+	if plan != nil {
+		if err := addPlanReport(data, plan); err != nil {
+			return nil, err
+		}
+	}
+
+	outputs, err := readReportFile(dir, outputsFile)
+	if err != nil {
+		return nil, err
+	}
+	if outputs != nil {
+		data[reportOutputsKey] = outputs
+	}
+
+	return data, nil
+}
+
+// addPlanReport adds the changes of a saved plan to the data of the report
+// Secret. When they are too large, attribute values are left out.
+func addPlanReport(data map[string][]byte, plan []byte) error {
+	planned, err := ParsePlannedChanges(plan)
+	if err != nil {
+		return err
+	}
+	changes, err := ParseResourceChanges(plan)
+	if err != nil {
+		return err
+	}
+
+	rawChanges, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	if len(rawChanges) > maxReportChangesSize {
+		for i := range changes {
+			changes[i].Attributes = nil
+		}
+		if rawChanges, err = json.Marshal(changes); err != nil {
+			return err
+		}
+	}
+	rawPlanned, err := json.Marshal(planned)
+	if err != nil {
+		return err
+	}
+	digest, err := planDigest(plan)
+	if err != nil {
+		return err
+	}
+
+	data[reportChangesKey] = rawChanges
+	data[reportPlannedKey] = rawPlanned
+	data[reportPlanDigestKey] = []byte(digest)
+	return nil
+}
+
+// readReportFile returns the content of a file of the report volume, or nil
+// when the runner container did not write it.
+func readReportFile(dir, name string) ([]byte, error) {
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return content, err
+}
+
+// WriteReport stores the report built from the files in dir into the report
+// Secret name, created empty by the controller along with the Job.
+func WriteReport(ctx context.Context, kube client.Client, name, namespace, dir string) error {
+	data, err := BuildReport(dir)
+	if err != nil {
+		return fmt.Errorf("failed to build report: %w", err)
+	}
+
+	return retry.Do(
+		func() error {
+			sec := corev1.Secret{}
+			if err := kube.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, &sec); err != nil {
+				return err
+			}
+
+			patch := client.MergeFrom(sec.DeepCopy())
+			sec.Data = data
+			return kube.Patch(ctx, &sec, patch)
+		},
+	)
+}
+
+// GetReport returns the report of a runner Job.
+func GetReport(ctx context.Context, kube client.Client, jobName, namespace string) (*Report, error) {
+	sec := corev1.Secret{}
+	err := kube.Get(ctx, client.ObjectKey{Name: ReportSecretName(jobName), Namespace: namespace}, &sec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report: %w", err)
+	}
+
+	report := &Report{PlanDigest: string(sec.Data[reportPlanDigestKey])}
+	if raw, ok := sec.Data[reportChangesKey]; ok {
+		if err := json.Unmarshal(raw, &report.Changes); err != nil {
+			return nil, fmt.Errorf("failed to parse resource changes: %w", err)
+		}
+	}
+	if raw, ok := sec.Data[reportPlannedKey]; ok {
+		report.Planned = &workspacev1alpha1.PlannedChanges{}
+		if err := json.Unmarshal(raw, report.Planned); err != nil {
+			return nil, fmt.Errorf("failed to parse planned changes: %w", err)
+		}
+	}
+	if raw, ok := sec.Data[reportOutputsKey]; ok {
+		if err := json.Unmarshal(raw, &report.Outputs); err != nil {
+			return nil, fmt.Errorf("failed to parse outputs: %w", err)
+		}
+	}
+
+	return report, nil
+}
+
+func (r *JobRunner) generateReportSecret() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ReportSecretName(r.Metadata.Name),
+			Namespace: r.Metadata.Namespace,
+		},
+		Data: map[string][]byte{},
+	}
+}
+
+// reporter returns the container storing the report of the runner Job into
+// its report Secret once OpenTofu is done.
+func (r *JobRunner) reporter(image string) corev1.Container {
+	return corev1.Container{
+		Name:    reporterContainer,
+		Image:   image,
+		Command: []string{"/bin/reporter"},
+		Args: []string{
+			"--secret=" + ReportSecretName(r.Metadata.Name),
+			"--namespace=" + r.Metadata.Namespace,
+			"--dir=" + reportPath,
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      reportVolume,
+				MountPath: reportPath,
+				ReadOnly:  true,
+			},
+		},
+	}
+}
@@ -0,0 +1,86 @@
+package opentofu
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestBuildReport(t *testing.T) {
+	const secret = "hunter2"
+	plan := `{"timestamp": "2024-01-01T00:00:00Z", "resource_changes": [{
+		"address": "a.b", "type": "a",
+		"change": {
+			"actions": ["update"],
+			"before": {"password": "old", "name": "x"},
+			"after": {"password": "` + secret + `", "name": "y"},
+			"before_sensitive": {"password": true},
+			"after_sensitive": {"password": true}
+		}
+	}]}`
+
+	tests := []struct {
+		name     string
+		files    map[string]string
+		wantKeys []string
+	}{
+		{
+			name:     "nothing written",
+			wantKeys: []string{},
+		},
+		{
+			name:     "plan",
+			files:    map[string]string{planFile: plan},
+			wantKeys: []string{reportChangesKey, reportPlanDigestKey, reportPlannedKey},
+		},
+		{
+			name: "plan with a sensitive resource value",
+			files: map[string]string{planFile: `{"resource_changes": [{
+				"address": "a.b", "type": "a",
+				"change": {
+					"actions": ["create"],
+					"before": null,
+					"after": {"password": "` + secret + `"},
+					"after_sensitive": true
+				}
+			}]}`},
+			wantKeys: []string{reportChangesKey, reportPlanDigestKey, reportPlannedKey},
+		},
+		{
+			name:     "outputs",
+			files:    map[string]string{outputsFile: `{"ip": {"sensitive": false, "value": "10.0.0.1"}}`},
+			wantKeys: []string{reportOutputsKey},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			data, err := BuildReport(dir)
+			if err != nil {
+				t.Fatalf("BuildReport() error = %v", err)
+			}
+
+			keys := []string{}
+			for key, value := range data {
+				keys = append(keys, key)
+				if bytes.Contains(value, []byte(secret)) {
+					t.Errorf("BuildReport() key %s holds a sensitive value: %s", key, value)
+				}
+			}
+			sort.Strings(keys)
+			if !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("BuildReport() keys = %v, want %v", keys, tt.wantKeys)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package opentofu
+
+import "strings"
+
+// shellQuote quotes s so that it is passed verbatim as a single word to the
+// sh -c command run by the runner containers.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+// shellArgs quotes args and joins them into a string to be appended to a
+// command, leading space included.
+func shellArgs(args []string) string {
+	var sb strings.Builder
+	for _, arg := range args {
+		sb.WriteString(" ")
+		sb.WriteString(shellQuote(arg))
+	}
+	return sb.String()
+}
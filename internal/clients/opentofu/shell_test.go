@@ -0,0 +1,59 @@
+package opentofu
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "", want: `''`},
+		{in: "plain", want: `'plain'`},
+		{in: "with space", want: `'with space'`},
+		{in: "it's", want: `'it'"'"'s'`},
+		{in: `$HOME "x" $(id) ; rm -rf /`, want: `'$HOME "x" $(id) ; rm -rf /'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got := shellQuote(tt.in)
+			if got != tt.want {
+				t.Errorf("shellQuote(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+
+			if _, err := exec.LookPath("sh"); err != nil {
+				return
+			}
+			out, err := exec.Command("sh", "-c", "printf %s "+got).Output()
+			if err != nil {
+				t.Fatalf("sh -c failed: %v", err)
+			}
+			if string(out) != tt.in {
+				t.Errorf("sh read %s as %q, want %q", got, out, tt.in)
+			}
+		})
+	}
+}
+
+func TestShellArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "none", args: nil, want: ""},
+		{name: "one", args: []string{"-refresh=false"}, want: ` '-refresh=false'`},
+		{name: "several", args: []string{"-var", "name=it's"}, want: ` '-var' 'name=it'"'"'s'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shellArgs(tt.args); got != tt.want {
+				t.Errorf("shellArgs(%q) = %s, want %s", tt.args, got, tt.want)
+			}
+		})
+	}
+}
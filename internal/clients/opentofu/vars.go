@@ -0,0 +1,41 @@
+package opentofu
+
+import (
+	"context"
+	"fmt"
+
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+	"github.com/krateoplatformops/opentofu-provider/internal/controllers/resolvers"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// resolveVarArgs resolves the vars files of the workspace into runner files
+// and returns the -var-file and -var flags to pass to OpenTofu. Vars are
+// passed as -var flags, which take precedence over any vars file.
+func resolveVarArgs(ctx context.Context, kube client.Client, cr workspacev1alpha1.Workspace, files *runnerFiles) ([]string, error) {
+	args := []string{}
+	params := cr.Spec.Workspace
+
+	for i, vf := range params.VarFiles {
+		content, err := resolvers.ResolveVarFile(ctx, kube, cr.GetNamespace(), vf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve vars file %d: %w", i, err)
+		}
+
+		name := fmt.Sprintf("varfile-%d.tfvars", i)
+		if vf.Format != nil && *vf.Format == workspacev1alpha1.VarFileFormatJSON {
+			name = fmt.Sprintf("varfile-%d.tfvars.json", i)
+		}
+
+		args = append(args, fmt.Sprintf("-var-file=%s", files.Add(name, content)))
+	}
+
+	for _, v := range params.Vars {
+		if v.Key == "" {
+			return nil, fmt.Errorf("var key must not be empty")
+		}
+		args = append(args, fmt.Sprintf("-var=%s=%s", v.Key, v.Value))
+	}
+
+	return args, nil
+}
@@ -0,0 +1,139 @@
+package opentofu
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	connectorv1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/tfconnector/v1alpha1"
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const defaultWorkingVolumeSize = "1Gi"
+
+// claimBindTimeout is how long the claims of a runner pod that cannot be
+// scheduled may stay unbound before ClaimError reports them.
+const claimBindTimeout = 2 * time.Minute
+
+// resolveWorkingVolume returns the working volume of a Workspace: the
+// fields set by the Workspace override those set by the TFConnector.
+func resolveWorkingVolume(cr workspacev1alpha1.Workspace, cfg *connectorv1alpha1.TFConnector) connectorv1alpha1.WorkingVolume {
+	v := connectorv1alpha1.WorkingVolume{}
+	for _, src := range []*connectorv1alpha1.WorkingVolume{cfg.Spec.WorkingVolume, cr.Spec.Workspace.WorkingVolume} {
+		if src == nil {
+			continue
+		}
+		if src.Type != "" {
+			v.Type = src.Type
+		}
+		if src.Size != nil {
+			v.Size = src.Size
+		}
+		if src.StorageClassName != nil {
+			v.StorageClassName = src.StorageClassName
+		}
+	}
+	if v.Type == "" {
+		v.Type = connectorv1alpha1.WorkingVolumeEphemeral
+	}
+	return v
+}
+
+// generateWorkingVolume returns the volume the runner works in.
+func (r *JobRunner) generateWorkingVolume(v connectorv1alpha1.WorkingVolume) corev1.Volume {
+	if v.Type == connectorv1alpha1.WorkingVolumeEmptyDir {
+		return corev1.Volume{
+			Name: r.Metadata.Name,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: v.Size},
+			},
+		}
+	}
+
+	pvc := r.generatePVC(v)
+	return corev1.Volume{
+		Name: pvc.GetName(),
+		VolumeSource: corev1.VolumeSource{
+			Ephemeral: &corev1.EphemeralVolumeSource{
+				VolumeClaimTemplate: &corev1.PersistentVolumeClaimTemplate{
+					Spec: pvc.Spec,
+				},
+			},
+		},
+	}
+}
+
+// ClaimError returns an error when a pod of a runner Job cannot be scheduled
+// because one of its claims has not been bound for longer than
+// claimBindTimeout, e.g. because its storage class does not exist.
+func ClaimError(ctx context.Context, kube client.Client, job *batchv1.Job) error {
+	pods := corev1.PodList{}
+	err := kube.List(ctx, &pods, &client.ListOptions{
+		Namespace:     job.GetNamespace(),
+		LabelSelector: labels.SelectorFromSet(labels.Set{"job-name": job.GetName()}),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodPending {
+			continue
+		}
+		unschedulable := ""
+		for _, c := range pod.Status.Conditions {
+			if c.Type == corev1.PodScheduled && c.Status == corev1.ConditionFalse && strings.Contains(c.Message, "PersistentVolumeClaim") {
+				unschedulable = c.Message
+			}
+		}
+		if unschedulable == "" {
+			continue
+		}
+
+		for _, vol := range pod.Spec.Volumes {
+			var name string
+			switch {
+			case vol.PersistentVolumeClaim != nil:
+				name = vol.PersistentVolumeClaim.ClaimName
+			case vol.Ephemeral != nil:
+				// Ephemeral claims are named after the pod and the volume.
+				name = fmt.Sprintf("%s-%s", pod.GetName(), vol.Name)
+			default:
+				continue
+			}
+
+			pvc := corev1.PersistentVolumeClaim{}
+			if err := kube.Get(ctx, client.ObjectKey{Namespace: pod.GetNamespace(), Name: name}, &pvc); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return err
+			}
+			if pvc.Status.Phase != corev1.ClaimPending || time.Since(pvc.GetCreationTimestamp().Time) < claimBindTimeout {
+				continue
+			}
+
+			hint := "set the storageClassName of the working volume, or use an EmptyDir one, if the cluster has no default StorageClass"
+			if sc := pvc.Spec.StorageClassName; sc != nil {
+				hint = fmt.Sprintf("check that the StorageClass %s exists", *sc)
+			}
+			return fmt.Errorf("claim %s is not bound after %s (%s): %s", name, claimBindTimeout, unschedulable, hint)
+		}
+	}
+
+	return nil
+}
+
+func storageRequest(size *resource.Quantity, def string) resource.Quantity {
+	if size != nil {
+		return size.DeepCopy()
+	}
+	return resource.MustParse(def)
+}
@@ -1,18 +1,19 @@
 package controllers
 
 import (
+	"github.com/krateoplatformops/opentofu-provider/internal/clients/opentofu"
 	"github.com/krateoplatformops/opentofu-provider/internal/controllers/workspace"
 	"github.com/krateoplatformops/provider-runtime/pkg/controller"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
-// Setup creates all controllers with the supplied logger and adds them to
-// the supplied manager.
-func Setup(mgr ctrl.Manager, o controller.Options) error {
-	for _, setup := range []func(ctrl.Manager, controller.Options) error{
+// Setup creates all controllers with the supplied logger and runner
+// defaults and adds them to the supplied manager.
+func Setup(mgr ctrl.Manager, o controller.Options, defaults opentofu.RunnerDefaults) error {
+	for _, setup := range []func(ctrl.Manager, controller.Options, opentofu.RunnerDefaults) error{
 		workspace.Setup,
 	} {
-		if err := setup(mgr, o); err != nil {
+		if err := setup(mgr, o, defaults); err != nil {
 			return err
 		}
 	}
@@ -2,9 +2,12 @@ package resolvers
 
 import (
 	"context"
+	"fmt"
 
 	connectorconfigs "github.com/krateoplatformops/opentofu-provider/apis/tfconnector/v1alpha1"
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
 	rtv1 "github.com/krateoplatformops/provider-runtime/apis/common/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -126,3 +129,74 @@ func ResolveTFConnector(ctx context.Context, kube client.Client, ref *rtv1.Refer
 
 	return &cfg, nil
 }
+
+// ResolveVarFile returns the content of the vars file from its source. The
+// source must be in namespace, the one of the Workspace.
+func ResolveVarFile(ctx context.Context, kube client.Client, namespace string, vf workspacev1alpha1.VarFile) ([]byte, error) {
+	switch vf.Source {
+	case workspacev1alpha1.VarFileSourceConfigMapKey:
+		if vf.ConfigMapKeyReference == nil {
+			return nil, fmt.Errorf("vars file source %s requires configMapKeyRef", vf.Source)
+		}
+		ref, err := localKeyReference(namespace, vf.ConfigMapKeyReference)
+		if err != nil {
+			return nil, err
+		}
+		value, err := GetConfigMapValue(ctx, kube, ref)
+		return []byte(value), err
+	case workspacev1alpha1.VarFileSourceSecretKey:
+		if vf.SecretKeyReference == nil {
+			return nil, fmt.Errorf("vars file source %s requires secretKeyRef", vf.Source)
+		}
+		ref, err := localKeyReference(namespace, vf.SecretKeyReference)
+		if err != nil {
+			return nil, err
+		}
+		return GetSecretValue(ctx, kube, ref)
+	default:
+		return nil, fmt.Errorf("unsupported vars file source: %s", vf.Source)
+	}
+}
+
+// localKeyReference returns ref in namespace, failing when ref points to
+// another namespace.
+func localKeyReference(namespace string, ref *workspacev1alpha1.KeyReference) (*workspacev1alpha1.KeyReference, error) {
+	if ref.Namespace != "" && ref.Namespace != namespace {
+		return nil, fmt.Errorf("cannot reference %s/%s: only resources in namespace %s can be referenced", ref.Namespace, ref.Name, namespace)
+	}
+	local := *ref
+	local.Namespace = namespace
+	return &local, nil
+}
+
+// GetConfigMapValue returns the value of the referenced ConfigMap key.
+func GetConfigMapValue(ctx context.Context, kube client.Client, ref *workspacev1alpha1.KeyReference) (string, error) {
+	cm := corev1.ConfigMap{}
+	err := kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, &cm)
+	if err != nil {
+		return "", fmt.Errorf("cannot get config map %s/%s: %w", ref.Namespace, ref.Name, err)
+	}
+
+	value, ok := cm.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in config map %s/%s", ref.Key, ref.Namespace, ref.Name)
+	}
+
+	return value, nil
+}
+
+// GetSecretValue returns the value of the referenced Secret key.
+func GetSecretValue(ctx context.Context, kube client.Client, ref *workspacev1alpha1.KeyReference) ([]byte, error) {
+	sec := corev1.Secret{}
+	err := kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, &sec)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get secret %s/%s: %w", ref.Namespace, ref.Name, err)
+	}
+
+	value, ok := sec.Data[ref.Key]
+	if !ok {
+		return nil, fmt.Errorf("key %s not found in secret %s/%s", ref.Key, ref.Namespace, ref.Name)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,86 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+
+	commonv1 "github.com/krateoplatformops/provider-runtime/apis/common/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+	"github.com/krateoplatformops/opentofu-provider/internal/clients/opentofu"
+)
+
+const reasonPlanPendingApproval = "PlanPendingApproval"
+
+// planConfigMapName returns the name of the ConfigMap storing the plan of
+// a Workspace waiting for approval.
+func planConfigMapName(cr *workspacev1alpha1.Workspace) string {
+	return fmt.Sprintf("%s-opentofu-plan", cr.GetName())
+}
+
+// planApproved returns true when the plan id is the one approved by the
+// annotation of the Workspace.
+func planApproved(cr *workspacev1alpha1.Workspace, id string) bool {
+	return cr.GetAnnotations()[workspacev1alpha1.AnnotationKeyApprovePlan] == id
+}
+
+// holdPlan stores the plan id, described in the logs of its InitPlan job,
+// and reports it as waiting for approval.
+func (e *external) holdPlan(ctx context.Context, cr *workspacev1alpha1.Workspace, id, logs, summary string) error {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            planConfigMapName(cr),
+			Namespace:       cr.GetNamespace(),
+			OwnerReferences: []metav1.OwnerReference{ownerReference(cr)},
+		},
+		Data: map[string]string{
+			"id":   id,
+			"plan": opentofu.PlanText(logs),
+		},
+	}
+	if err := opentofu.InstallConfigMap(ctx, e.kube, cm); err != nil {
+		return fmt.Errorf("failed to store plan: %w", err)
+	}
+
+	if cr.Status.AtProvider.PendingPlanID != id {
+		e.recorder.Eventf(cr, corev1.EventTypeNormal, reasonPlanPendingApproval,
+			"plan %s is waiting for approval: %s", id, summary)
+	}
+
+	cr.Status.AtProvider.PendingPlanID = id
+	cr.SetConditions(pendingApproval(id, summary))
+
+	return nil
+}
+
+// clearPendingPlan reports that no plan is waiting for approval anymore.
+func clearPendingPlan(cr *workspacev1alpha1.Workspace, reason commonv1.ConditionReason) {
+	if !cr.Spec.Workspace.RequireApproval && cr.Status.AtProvider.PendingPlanID == "" {
+		return
+	}
+
+	cr.Status.AtProvider.PendingPlanID = ""
+	cr.SetConditions(noPendingApproval(reason))
+}
+
+// consumeApproval removes the approval annotation of a Workspace, returning
+// an error when it does not approve the plan waiting for approval.
+func (e *external) consumeApproval(ctx context.Context, cr *workspacev1alpha1.Workspace) error {
+	id := cr.Status.AtProvider.PendingPlanID
+	if id == "" || cr.GetAnnotations()[workspacev1alpha1.AnnotationKeyApprovePlan] != id {
+		return fmt.Errorf("plan %q is not approved", id)
+	}
+
+	annotations := cr.GetAnnotations()
+	delete(annotations, workspacev1alpha1.AnnotationKeyApprovePlan)
+	cr.SetAnnotations(annotations)
+	if err := e.kube.Update(ctx, cr); err != nil {
+		return fmt.Errorf("failed to clear approval: %w", err)
+	}
+
+	clearPendingPlan(cr, reasonPlanApproved)
+
+	return nil
+}
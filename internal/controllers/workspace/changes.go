@@ -0,0 +1,50 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+	"github.com/krateoplatformops/opentofu-provider/internal/clients/opentofu"
+)
+
+const resourceChangesKey = "changes.json"
+
+// changesConfigMapName returns the name of the ConfigMap storing the
+// resource changes of the last plan of a Workspace.
+func changesConfigMapName(cr *workspacev1alpha1.Workspace) string {
+	return fmt.Sprintf("%s-opentofu-changes", cr.GetName())
+}
+
+// publishResourceChanges stores the resource changes of the last plan of a
+// Workspace, as redacted, and trimmed when too large, by the runner.
+func (e *external) publishResourceChanges(ctx context.Context, cr *workspacev1alpha1.Workspace, changes []opentofu.ResourceChange) error {
+	if changes == nil {
+		changes = []opentofu.ResourceChange{}
+	}
+
+	data, err := json.MarshalIndent(changes, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            changesConfigMapName(cr),
+			Namespace:       cr.GetNamespace(),
+			OwnerReferences: []metav1.OwnerReference{ownerReference(cr)},
+		},
+		Data: map[string]string{
+			resourceChangesKey: string(data),
+		},
+	}
+	if err := opentofu.InstallConfigMap(ctx, e.kube, cm); err != nil {
+		return fmt.Errorf("failed to store resource changes: %w", err)
+	}
+
+	return nil
+}
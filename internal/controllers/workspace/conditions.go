@@ -0,0 +1,140 @@
+package workspace
+
+import (
+	"fmt"
+	"strings"
+
+	commonv1 "github.com/krateoplatformops/provider-runtime/apis/common/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+)
+
+// TypeDriftDetected is the condition reporting whether the last plan of a
+// Workspace found changes that are not applied yet.
+const TypeDriftDetected commonv1.ConditionType = "DriftDetected"
+
+const (
+	reasonPlanHasChanges commonv1.ConditionReason = "PlanHasChanges"
+	reasonPlanUpToDate   commonv1.ConditionReason = "PlanUpToDate"
+)
+
+// driftDetected returns a condition reporting the changes of a plan that
+// were not applied.
+func driftDetected(summary string) commonv1.Condition {
+	return commonv1.Condition{
+		Type:               TypeDriftDetected,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reasonPlanHasChanges,
+		Message:            summary,
+	}
+}
+
+// noDrift returns a condition reporting that the last plan found no changes.
+func noDrift() commonv1.Condition {
+	return commonv1.Condition{
+		Type:               TypeDriftDetected,
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reasonPlanUpToDate,
+	}
+}
+
+// TypePendingApproval is the condition reporting whether a plan of a
+// Workspace requiring approval is waiting for it.
+const TypePendingApproval commonv1.ConditionType = "PendingApproval"
+
+const (
+	reasonAwaitingApproval commonv1.ConditionReason = "AwaitingApproval"
+	reasonPlanApproved     commonv1.ConditionReason = "PlanApproved"
+)
+
+// pendingApproval returns a condition reporting the plan waiting for
+// approval.
+func pendingApproval(id, summary string) commonv1.Condition {
+	return commonv1.Condition{
+		Type:               TypePendingApproval,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reasonAwaitingApproval,
+		Message:            fmt.Sprintf("%s Set the %s annotation to %s to apply it.", summary, workspacev1alpha1.AnnotationKeyApprovePlan, id),
+	}
+}
+
+// noPendingApproval returns a condition reporting that no plan is waiting
+// for approval, for the given reason.
+func noPendingApproval(reason commonv1.ConditionReason) commonv1.Condition {
+	return commonv1.Condition{
+		Type:               TypePendingApproval,
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+	}
+}
+
+// TypeBlocked is the condition reporting whether the guardrails of a
+// Workspace block applying its last plan.
+const TypeBlocked commonv1.ConditionType = "Blocked"
+
+const (
+	reasonGuardrailViolated    commonv1.ConditionReason = "GuardrailViolated"
+	reasonGuardrailsPassed     commonv1.ConditionReason = "GuardrailsPassed"
+	reasonGuardrailsOverridden commonv1.ConditionReason = "GuardrailsOverridden"
+)
+
+// blocked returns a condition reporting the guardrails violated by the plan
+// with the given ID.
+func blocked(id string, violations []string) commonv1.Condition {
+	return commonv1.Condition{
+		Type:               TypeBlocked,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reasonGuardrailViolated,
+		Message: fmt.Sprintf("%s. Set the %s annotation to %s to apply the plan anyway.",
+			strings.Join(violations, "; "), workspacev1alpha1.AnnotationKeyOverrideGuardrails, id),
+	}
+}
+
+// notBlocked returns a condition reporting that the guardrails do not block
+// the last plan, for the given reason.
+func notBlocked(reason commonv1.ConditionReason) commonv1.Condition {
+	return commonv1.Condition{
+		Type:               TypeBlocked,
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+	}
+}
+
+// TypeCreateGated is the condition reporting whether the management policy
+// of a Workspace did not allow creating it, so that it was only planned.
+const TypeCreateGated commonv1.ConditionType = "CreateGated"
+
+const (
+	reasonCreateNotAllowed commonv1.ConditionReason = "CreateNotAllowed"
+	reasonCreateApplied    commonv1.ConditionReason = "CreateApplied"
+)
+
+// createGated returns a condition reporting that the Workspace was planned
+// rather than created.
+func createGated() commonv1.Condition {
+	return commonv1.Condition{
+		Type:               TypeCreateGated,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reasonCreateNotAllowed,
+		Message:            "The management policy does not allow create: the Workspace is only planned.",
+	}
+}
+
+// notCreateGated returns a condition reporting that the Workspace was
+// created by an apply.
+func notCreateGated() commonv1.Condition {
+	return commonv1.Condition{
+		Type:               TypeCreateGated,
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reasonCreateApplied,
+	}
+}
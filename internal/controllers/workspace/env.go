@@ -0,0 +1,29 @@
+package workspace
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+	"github.com/krateoplatformops/opentofu-provider/internal/clients/opentofu"
+)
+
+const reasonEnvOverridden = "EnvOverridden"
+
+// reportEnvConflicts emits a Warning event listing the env vars of the
+// Workspace that are also set by its TFConnector.
+func (e *external) reportEnvConflicts(ctx context.Context, cr *workspacev1alpha1.Workspace) {
+	conflicts, err := opentofu.EnvConflicts(ctx, e.kube, *cr)
+	if err != nil {
+		e.log.Debug("Cannot check env vars conflicts", "name", cr.GetName(), "error", err)
+		return
+	}
+	if len(conflicts) == 0 {
+		return
+	}
+
+	e.recorder.Eventf(cr, corev1.EventTypeWarning, reasonEnvOverridden,
+		"env vars set by both the Workspace and the TFConnector: %s", strings.Join(conflicts, ", "))
+}
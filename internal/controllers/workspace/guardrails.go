@@ -0,0 +1,44 @@
+package workspace
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+	"github.com/krateoplatformops/opentofu-provider/internal/clients/opentofu"
+)
+
+const reasonPlanBlocked = "PlanBlocked"
+
+// checkGuardrails evaluates the guardrails of a Workspace against the plan
+// id reported by an InitPlan job and returns true when they block applying
+// it. A plan whose ID is set by the override annotation is never blocked.
+func (e *external) checkGuardrails(cr *workspacev1alpha1.Workspace, id string, changes []opentofu.ResourceChange, planned *workspacev1alpha1.PlannedChanges) (bool, error) {
+	guardrails := cr.Spec.Workspace.Guardrails
+	if guardrails == nil {
+		return false, nil
+	}
+
+	violations, err := opentofu.CheckGuardrails(guardrails, changes, planned)
+	if err != nil {
+		return false, fmt.Errorf("failed to check guardrails: %w", err)
+	}
+	if len(violations) == 0 {
+		cr.SetConditions(notBlocked(reasonGuardrailsPassed))
+		return false, nil
+	}
+
+	if cr.GetAnnotations()[workspacev1alpha1.AnnotationKeyOverrideGuardrails] == id {
+		cr.SetConditions(notBlocked(reasonGuardrailsOverridden))
+		return false, nil
+	}
+
+	if c := cr.GetCondition(TypeBlocked); c.Reason != reasonGuardrailViolated || c.Message != blocked(id, violations).Message {
+		e.recorder.Eventf(cr, corev1.EventTypeWarning, reasonPlanBlocked,
+			"plan %s is blocked by guardrails: %v", id, violations)
+	}
+	cr.SetConditions(blocked(id, violations))
+
+	return true, nil
+}
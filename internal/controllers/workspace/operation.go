@@ -0,0 +1,54 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/krateoplatformops/provider-runtime/pkg/meta"
+	batchv1 "k8s.io/api/batch/v1"
+
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+	"github.com/krateoplatformops/opentofu-provider/internal/clients/opentofu"
+)
+
+// requestedOperation returns the Operation requested for a Workspace, or
+// nil when there is none or it cannot be applied.
+func requestedOperation(cr *workspacev1alpha1.Workspace) *opentofu.Operation {
+	if !meta.IsActionAllowed(cr, meta.ActionUpdate) {
+		return nil
+	}
+	return opentofu.GetOperation(*cr)
+}
+
+// planMatchesOperation returns whether a plan job planned the Operation
+// requested for a Workspace, or no Operation when none is requested.
+func planMatchesOperation(cr *workspacev1alpha1.Workspace, job *batchv1.Job) bool {
+	op := requestedOperation(cr)
+	if op == nil {
+		return opentofu.JobOperation(job) == ""
+	}
+	return opentofu.JobOperation(job) == op.String()
+}
+
+// clearOperation removes the annotations requesting an Operation from a
+// Workspace, leaving its status as is.
+func (e *external) clearOperation(ctx context.Context, cr *workspacev1alpha1.Workspace) error {
+	status := cr.Status.DeepCopy()
+	opentofu.ClearOperation(cr)
+	if err := e.kube.Update(ctx, cr); err != nil {
+		return fmt.Errorf("failed to clear operation: %w", err)
+	}
+	cr.Status = *status
+	return nil
+}
+
+// clearJobOperation removes the Operation run by a Job from a Workspace
+// when it is still requested, e.g. because removing it failed when the Job
+// started.
+func (e *external) clearJobOperation(ctx context.Context, cr *workspacev1alpha1.Workspace, job *batchv1.Job) error {
+	op := opentofu.GetOperation(*cr)
+	if op == nil || op.String() != opentofu.JobOperation(job) {
+		return nil
+	}
+	return e.clearOperation(ctx, cr)
+}
@@ -0,0 +1,101 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+
+	commonv1 "github.com/krateoplatformops/provider-runtime/apis/common/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+	"github.com/krateoplatformops/opentofu-provider/internal/clients/opentofu"
+)
+
+// publishOutputs records the non-sensitive outputs reported by an
+// InitApply job in the status of the Workspace, and writes all of them to
+// its connection secret, if any.
+func (e *external) publishOutputs(ctx context.Context, cr *workspacev1alpha1.Workspace, outputs map[string]opentofu.Output) error {
+	if outputs == nil {
+		return fmt.Errorf("no outputs reported by apply job")
+	}
+
+	data := make(map[string][]byte, len(outputs))
+	cr.Status.AtProvider.Outputs = map[string]string{}
+	for name, output := range outputs {
+		value := output.String()
+		data[name] = []byte(value)
+		if !output.Sensitive {
+			cr.Status.AtProvider.Outputs[name] = value
+		}
+	}
+
+	ref := cr.Spec.WriteConnectionSecretToRef
+	if ref == nil {
+		return nil
+	}
+
+	return e.writeConnectionSecret(ctx, cr, ref, data)
+}
+
+// writeConnectionSecret writes data to the connection secret of a
+// Workspace. The Secret must be in the namespace of the Workspace and, when
+// it exists, be owned by it, so that a Workspace cannot overwrite Secrets
+// of others.
+func (e *external) writeConnectionSecret(ctx context.Context, cr *workspacev1alpha1.Workspace, ref *commonv1.Reference, data map[string][]byte) error {
+	if ref.Namespace != cr.GetNamespace() {
+		return fmt.Errorf("connection secret must be in namespace %s, not %s", cr.GetNamespace(), ref.Namespace)
+	}
+
+	sec := &corev1.Secret{}
+	err := e.kube.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: ref.Namespace}, sec)
+	if apierrors.IsNotFound(err) {
+		sec = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            ref.Name,
+				Namespace:       ref.Namespace,
+				OwnerReferences: []metav1.OwnerReference{ownerReference(cr)},
+			},
+			Data: data,
+		}
+		if err := e.kube.Create(ctx, sec); err != nil {
+			return fmt.Errorf("failed to create connection secret: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get connection secret: %w", err)
+	}
+
+	if !ownedBy(sec, cr) {
+		return fmt.Errorf("connection secret %s is not owned by the workspace", ref.Name)
+	}
+
+	sec.Data = data
+	if err := e.kube.Update(ctx, sec); err != nil {
+		return fmt.Errorf("failed to update connection secret: %w", err)
+	}
+	return nil
+}
+
+// ownedBy returns whether obj has an owner reference to a Workspace.
+func ownedBy(obj metav1.Object, cr *workspacev1alpha1.Workspace) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == cr.GetUID() {
+			return true
+		}
+	}
+	return false
+}
+
+// ownerReference returns an owner reference to a Workspace.
+func ownerReference(cr *workspacev1alpha1.Workspace) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: workspacev1alpha1.SchemeGroupVersion.String(),
+		Kind:       workspacev1alpha1.WorkspaceKind,
+		Name:       cr.GetName(),
+		UID:        cr.GetUID(),
+	}
+}
@@ -0,0 +1,22 @@
+package workspace
+
+import (
+	"github.com/krateoplatformops/provider-runtime/pkg/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+)
+
+// createAllowed returns whether the management policy of a Workspace allows
+// applying the planned changes as far as create is concerned. Plans adding
+// resources create them, and so does the first apply of a Workspace whose
+// create was not allowed.
+func createAllowed(cr *workspacev1alpha1.Workspace, planned *workspacev1alpha1.PlannedChanges) bool {
+	if meta.IsActionAllowed(cr, meta.ActionCreate) {
+		return true
+	}
+	if cr.Status.GetCondition(TypeCreateGated).Status == metav1.ConditionTrue {
+		return false
+	}
+	return planned != nil && planned.Add == 0
+}
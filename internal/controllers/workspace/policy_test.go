@@ -0,0 +1,41 @@
+package workspace
+
+import (
+	"testing"
+
+	"github.com/krateoplatformops/provider-runtime/pkg/meta"
+
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+)
+
+func TestCreateAllowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		gated   bool
+		planned *workspacev1alpha1.PlannedChanges
+		want    bool
+	}{
+		{name: "create allowed", planned: &workspacev1alpha1.PlannedChanges{Add: 1}, want: true},
+		{name: "create allowed after a gated create", gated: true, want: true},
+		{name: "plan adding resources", policy: meta.ManagementPolicyObserveDelete, planned: &workspacev1alpha1.PlannedChanges{Add: 1}, want: false},
+		{name: "plan changing resources", policy: meta.ManagementPolicyObserveDelete, planned: &workspacev1alpha1.PlannedChanges{Change: 1, Destroy: 1}, want: true},
+		{name: "unknown plan", policy: meta.ManagementPolicyObserveDelete, want: false},
+		{name: "gated create", policy: meta.ManagementPolicyObserveDelete, gated: true, planned: &workspacev1alpha1.PlannedChanges{Change: 1}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &workspacev1alpha1.Workspace{}
+			if tt.policy != "" {
+				cr.SetAnnotations(map[string]string{meta.AnnotationKeyManagementPolicy: tt.policy})
+			}
+			if tt.gated {
+				cr.SetConditions(createGated())
+			}
+			if got := createAllowed(cr, tt.planned); got != tt.want {
+				t.Errorf("createAllowed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	worspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+	"github.com/krateoplatformops/opentofu-provider/internal/clients/opentofu"
 	"github.com/krateoplatformops/provider-runtime/pkg/controller"
 	"github.com/krateoplatformops/provider-runtime/pkg/event"
 	"github.com/krateoplatformops/provider-runtime/pkg/logging"
@@ -24,6 +25,9 @@ type connector struct {
 	log      logging.Logger
 	recorder record.EventRecorder
 
+	// defaults of the runner Jobs.
+	defaults opentofu.RunnerDefaults
+
 	// fs     afero.Afero
 	// initTf func(dir string, verbose bool) tfclient
 }
@@ -32,10 +36,11 @@ type external struct {
 	log      logging.Logger
 	recorder record.EventRecorder
 	kube     client.Client
+	defaults opentofu.RunnerDefaults
 }
 
 // Setup adds a controller that reconciles Token managed resources.
-func Setup(mgr ctrl.Manager, o controller.Options) error {
+func Setup(mgr ctrl.Manager, o controller.Options, defaults opentofu.RunnerDefaults) error {
 	_ = apiextensionsscheme.AddToScheme(clientsetscheme.Scheme)
 
 	name := reconciler.ControllerName(worspacev1alpha1.WorkspaceGroupKind)
@@ -50,6 +55,7 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 			kube:     mgr.GetClient(),
 			log:      log,
 			recorder: recorder,
+			defaults: defaults,
 		}),
 		reconciler.WithPollInterval(o.PollInterval),
 		reconciler.WithLogger(log),
@@ -72,5 +78,11 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (reconcile
 		log:      c.log,
 		recorder: c.recorder,
 		kube:     c.kube,
+		defaults: c.defaults,
 	}, nil
 }
+
+// run starts the runner Job of an Action for a Workspace.
+func (e *external) run(ctx context.Context, cr *worspacev1alpha1.Workspace, action opentofu.Action, opts ...opentofu.RunOption) error {
+	return opentofu.Run(ctx, e.kube, *cr.DeepCopy(), action, append(opts, opentofu.WithDefaults(e.defaults))...)
+}
@@ -0,0 +1,37 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+
+	commonv1 "github.com/krateoplatformops/provider-runtime/apis/common/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	workspacev1alpha1 "github.com/krateoplatformops/opentofu-provider/apis/workspace/v1alpha1"
+)
+
+const reasonRunnerUnschedulable = "RunnerUnschedulable"
+
+// abortJob deletes a runner Job that cannot run and reports why, so that
+// the Workspace is run again once its settings are fixed.
+func (e *external) abortJob(ctx context.Context, cr *workspacev1alpha1.Workspace, job *batchv1.Job, cause error) error {
+	deletePropagation := metav1.DeletePropagationForeground
+	if err := e.kube.Delete(ctx, job, &client.DeleteOptions{PropagationPolicy: &deletePropagation}); err != nil {
+		return err
+	}
+
+	e.recorder.Eventf(cr, corev1.EventTypeWarning, reasonRunnerUnschedulable,
+		"job %s cannot run: %s", job.GetName(), cause)
+
+	strErr := fmt.Errorf("job %s cannot run: %w", job.GetName(), cause).Error()
+	cr.SetConditions(commonv1.Unavailable())
+	cr.Status.Error = &strErr
+	if err := e.kube.Status().Update(ctx, cr); err != nil {
+		return err
+	}
+
+	return fmt.Errorf("job %s cannot run: %w", job.GetName(), cause)
+}
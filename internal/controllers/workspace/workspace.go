@@ -39,6 +39,10 @@ const (
 	reasonUpdated = "UpdatedExternalResource"
 	reasonCreated = "CreatedExternalResource"
 	reasonDeleted = "DeletedExternalResource"
+
+	reasonOperationStarted   = "OperationStarted"
+	reasonOperationSucceeded = "OperationSucceeded"
+	reasonOperationFailed    = "OperationFailed"
 )
 
 func (e *external) Observe(ctx context.Context, mg resource.Managed) (reconciler.ExternalObservation, error) {
@@ -102,15 +106,113 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (reconciler
 			}
 
 			exitCode := completedPod.Status.ContainerStatuses[0].State.Terminated.ExitCode
+			if jobInfo.Logs != nil {
+				cr.Status.AtProvider.OpenTofuVersion = opentofu.ParseVersion(*jobInfo.Logs)
+			}
+
+			report, err := opentofu.GetReport(ctx, e.kube, job.GetName(), job.GetNamespace())
+			if err != nil {
+				return reconciler.ExternalObservation{}, err
+			}
 
 			deletePropagation := metav1.DeletePropagationForeground
 			if err = e.kube.Delete(ctx, job, &client.DeleteOptions{PropagationPolicy: &deletePropagation}); err != nil {
 				return reconciler.ExternalObservation{}, err
 			}
 
+			// Only the plan of the requested Operation, if any, may be applied.
+			if exitCode == 0 && !planMatchesOperation(cr, job) {
+				e.log.Info("Plan does not match the requested operation, planning again", "name", cr.GetName())
+				cr.SetConditions(commonv1.Available())
+				return reconciler.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				}, e.kube.Status().Update(ctx, cr)
+			}
+
 			if exitCode == 0 {
-				if opentofu.ClassifyPlanPodLog(*jobInfo.Logs) {
+				changes, err := opentofu.PlanHasChanges(completedPod)
+				if err != nil {
+					return reconciler.ExternalObservation{}, err
+				}
+
+				if !changes {
+					if op := opentofu.JobOperation(job); op != "" {
+						if err := e.clearOperation(ctx, cr); err != nil {
+							return reconciler.ExternalObservation{}, err
+						}
+						e.recorder.Eventf(cr, corev1.EventTypeNormal, reasonOperationSucceeded,
+							"opentofu apply %s found no changes", op)
+					}
+					if err := e.publishResourceChanges(ctx, cr, nil); err != nil {
+						return reconciler.ExternalObservation{}, err
+					}
+
 					e.log.Info("Workspace is up to date", "name", cr.GetName())
+					cr.SetConditions(commonv1.Available(), noDrift())
+					cr.Status.AtProvider.DriftDetected = false
+					cr.Status.AtProvider.PlannedChanges = nil
+					clearPendingPlan(cr, reasonPlanUpToDate)
+					if cr.Spec.Workspace.Guardrails != nil {
+						cr.SetConditions(notBlocked(reasonGuardrailsPassed))
+					}
+					cr.Status.Error = nil
+					return reconciler.ExternalObservation{
+						ResourceExists:   true,
+						ResourceUpToDate: true,
+					}, e.kube.Status().Update(ctx, cr)
+				}
+
+				planned, resourceChanges := report.Planned, report.Changes
+				if planned == nil {
+					return reconciler.ExternalObservation{}, fmt.Errorf("no plan reported by plan job")
+				}
+				planID := opentofu.PlanID(cr.GetGeneration(), report.PlanDigest)
+				if err := e.publishResourceChanges(ctx, cr, resourceChanges); err != nil {
+					return reconciler.ExternalObservation{}, err
+				}
+
+				summary := opentofu.PlanSummary(planned)
+				cr.SetConditions(driftDetected(summary))
+				cr.Status.AtProvider.DriftDetected = true
+				cr.Status.AtProvider.PlannedChanges = planned
+
+				// The changes are only reported when they cannot be applied.
+				if !meta.IsActionAllowed(cr, meta.ActionUpdate) || !createAllowed(cr, planned) {
+					e.log.Info("Workspace drifted, update or create action is not allowed", "name", cr.GetName())
+					cr.SetConditions(commonv1.Available())
+					cr.Status.Error = nil
+					return reconciler.ExternalObservation{
+						ResourceExists:   true,
+						ResourceUpToDate: true,
+					}, e.kube.Status().Update(ctx, cr)
+				}
+
+				isBlocked, err := e.checkGuardrails(cr, planID, resourceChanges, planned)
+				if err != nil {
+					return reconciler.ExternalObservation{}, err
+				}
+				if isBlocked {
+					e.log.Info("Workspace plan is blocked by guardrails", "name", cr.GetName())
+					cr.SetConditions(commonv1.Available())
+					cr.Status.Error = nil
+					return reconciler.ExternalObservation{
+						ResourceExists:   true,
+						ResourceUpToDate: true,
+					}, e.kube.Status().Update(ctx, cr)
+				}
+
+				// Operations are explicit requests and need no approval.
+				if cr.Spec.Workspace.RequireApproval && requestedOperation(cr) == nil && !planApproved(cr, planID) {
+					e.log.Info("Workspace plan is waiting for approval", "name", cr.GetName())
+					// The plan is held even when the runner logs cannot be read.
+					logs := ""
+					if jobInfo.Logs != nil {
+						logs = *jobInfo.Logs
+					}
+					if err := e.holdPlan(ctx, cr, planID, logs, summary); err != nil {
+						return reconciler.ExternalObservation{}, err
+					}
 					cr.SetConditions(commonv1.Available())
 					cr.Status.Error = nil
 					return reconciler.ExternalObservation{
@@ -123,7 +225,7 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (reconciler
 				return reconciler.ExternalObservation{
 					ResourceExists:   true,
 					ResourceUpToDate: false,
-				}, nil
+				}, e.kube.Status().Update(ctx, cr)
 			}
 
 			cr.SetConditions(commonv1.Unavailable())
@@ -151,6 +253,11 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (reconciler
 
 			return reconciler.ExternalObservation{}, fmt.Errorf("job failed: %s", *jobInfo.Errs)
 		} else {
+			if cond.Reason != commonv1.ReasonDeleting {
+				if err := opentofu.ClaimError(ctx, e.kube, job); err != nil {
+					return reconciler.ExternalObservation{}, e.abortJob(ctx, cr, job, err)
+				}
+			}
 			cr.SetConditions(observingCondition)
 			return reconciler.ExternalObservation{
 				ResourceExists:   true,
@@ -165,8 +272,14 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (reconciler
 		e.log.Debug("Checking if workspace is up to date", "name", cr.GetName())
 		job, err := opentofu.GetJob(ctx, e.kube, opentofu.JobNamer(cr.ObjectMeta, opentofu.InitPlan), cr.GetNamespace())
 		if apierrors.IsNotFound(err) || job == nil {
+			// Operations are planned first, so that guardrails check them too.
+			var opts []opentofu.RunOption
+			if op := requestedOperation(cr); op != nil {
+				e.log.Info("Operation requested", "name", cr.GetName())
+				opts = append(opts, opentofu.WithOperation(*op))
+			}
 
-			err := opentofu.Run(ctx, e.kube, *cr.DeepCopy(), opentofu.InitPlan)
+			err := e.run(ctx, cr, opentofu.InitPlan, opts...)
 			if err != nil {
 				return reconciler.ExternalObservation{}, fmt.Errorf("failed to plan: %w", err)
 			}
@@ -202,13 +315,43 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (reconciler
 				return reconciler.ExternalObservation{}, err
 			}
 		} else if job.Status.Succeeded == 1 {
+			jobInfo, err := opentofu.GetJobInfo(ctx, e.kube, job.GetName(), job.GetNamespace())
+			if err != nil {
+				return reconciler.ExternalObservation{}, err
+			}
+
+			report, err := opentofu.GetReport(ctx, e.kube, job.GetName(), job.GetNamespace())
+			if err != nil {
+				return reconciler.ExternalObservation{}, err
+			}
+			if err := e.publishOutputs(ctx, cr, report.Outputs); err != nil {
+				return reconciler.ExternalObservation{}, fmt.Errorf("failed to publish outputs: %w", err)
+			}
+
 			deletePropagation := metav1.DeletePropagationForeground
 			if err = e.kube.Delete(ctx, job, &client.DeleteOptions{PropagationPolicy: &deletePropagation}); err != nil {
 				return reconciler.ExternalObservation{}, err
 			}
+			if op := opentofu.JobOperation(job); op != "" {
+				if err := e.clearJobOperation(ctx, cr, job); err != nil {
+					return reconciler.ExternalObservation{}, err
+				}
+				e.recorder.Eventf(cr, corev1.EventTypeNormal, reasonOperationSucceeded,
+					"opentofu apply %s succeeded", op)
+			}
+
 			e.log.Debug("Setting available condition - job succeeded")
-			cr.SetConditions(commonv1.Available())
+			cr.SetConditions(commonv1.Available(), noDrift())
+			if cr.Status.GetCondition(TypeCreateGated).Status == metav1.ConditionTrue {
+				cr.SetConditions(notCreateGated())
+			}
+			cr.Status.AtProvider.DriftDetected = false
+			cr.Status.AtProvider.PlannedChanges = nil
 			cr.Status.Error = nil
+			cr.Status.AtProvider.Commit = jobInfo.GetCommit()
+			if jobInfo.Logs != nil {
+				cr.Status.AtProvider.OpenTofuVersion = opentofu.ParseVersion(*jobInfo.Logs)
+			}
 			return reconciler.ExternalObservation{
 				ResourceExists:   true,
 				ResourceUpToDate: true,
@@ -225,6 +368,14 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (reconciler
 				return reconciler.ExternalObservation{}, err
 			}
 
+			if op := opentofu.JobOperation(job); op != "" {
+				if err := e.clearJobOperation(ctx, cr, job); err != nil {
+					return reconciler.ExternalObservation{}, err
+				}
+				e.recorder.Eventf(cr, corev1.EventTypeWarning, reasonOperationFailed,
+					"opentofu apply %s failed: %s", op, *jobInfo.Errs)
+			}
+
 			strErr := fmt.Errorf("job failed: %s", *jobInfo.Errs).Error()
 
 			cr.Status.Error = &strErr
@@ -236,6 +387,11 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (reconciler
 
 			return reconciler.ExternalObservation{}, fmt.Errorf("job failed: %s", *jobInfo.Errs)
 		} else {
+			if cond.Reason != commonv1.ReasonDeleting {
+				if err := opentofu.ClaimError(ctx, e.kube, job); err != nil {
+					return reconciler.ExternalObservation{}, e.abortJob(ctx, cr, job, err)
+				}
+			}
 			return reconciler.ExternalObservation{
 				ResourceExists:   true,
 				ResourceUpToDate: true,
@@ -255,7 +411,7 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (reconciler
 		applyJob, applyErr := opentofu.GetJob(ctx, e.kube, opentofu.JobNamer(cr.ObjectMeta, opentofu.InitApply), cr.GetNamespace())
 		if (apierrors.IsNotFound(planErr) || planJob == nil) && (apierrors.IsNotFound(applyErr) || applyJob == nil) && (job == nil) {
 			e.log.Debug("Running destroy job", "name", cr.GetName())
-			err := opentofu.Run(ctx, e.kube, *cr.DeepCopy(), opentofu.InitDestroy)
+			err := e.run(ctx, cr, opentofu.InitDestroy)
 			if err != nil {
 				return reconciler.ExternalObservation{}, fmt.Errorf("failed to destroy: %w", err)
 			}
@@ -344,9 +500,30 @@ func (e *external) Create(ctx context.Context, mg resource.Managed) error {
 		return nil
 	}
 
+	// Existing infrastructure is only planned, to report its drift. Workspaces
+	// requiring approval or with guardrails plan too, so that nothing is
+	// applied before its plan is approved and checked.
+	if !meta.IsActionAllowed(cr, meta.ActionCreate) || cr.Spec.Workspace.RequireApproval || cr.Spec.Workspace.Guardrails != nil {
+		e.log.Info("Planning before applying", "name", cr.GetName())
+
+		err := e.run(ctx, cr, opentofu.InitPlan)
+		if err != nil {
+			return fmt.Errorf("failed to plan: %w", err)
+		}
+
+		cr.Status.SetConditions(observingCondition)
+		if !meta.IsActionAllowed(cr, meta.ActionCreate) {
+			cr.Status.SetConditions(createGated())
+		}
+
+		return e.kube.Status().Update(ctx, cr)
+	}
+
 	e.log.Info("Creating", "name", cr.GetName())
 
-	err := opentofu.Run(ctx, e.kube, *cr.DeepCopy(), opentofu.InitApply)
+	e.reportEnvConflicts(ctx, cr)
+
+	err := e.run(ctx, cr, opentofu.InitApply)
 	if err != nil {
 		return fmt.Errorf("failed to apply: %w", err)
 	}
@@ -365,13 +542,44 @@ func (e *external) Update(ctx context.Context, mg resource.Managed) error {
 		return errors.New(errNotWorkspace)
 	}
 
+	if !meta.IsActionAllowed(cr, meta.ActionUpdate) {
+		return fmt.Errorf("update action is not allowed")
+	}
+	if !createAllowed(cr, cr.Status.AtProvider.PlannedChanges) {
+		return fmt.Errorf("create action is not allowed")
+	}
+
 	e.log.Info("Update", "name", cr.GetName())
 
-	err := opentofu.Run(ctx, e.kube, *cr.DeepCopy(), opentofu.InitApply)
+	e.reportEnvConflicts(ctx, cr)
+
+	// Approvals are consumed before the apply starts, and operations once it
+	// started, so that they are used once. Operations are explicit requests
+	// and need no approval.
+	var opts []opentofu.RunOption
+	op := opentofu.GetOperation(*cr)
+	if op == nil && cr.Spec.Workspace.RequireApproval {
+		if err := e.consumeApproval(ctx, cr); err != nil {
+			return err
+		}
+	}
+	if op != nil {
+		opts = append(opts, opentofu.WithOperation(*op))
+	}
+
+	err := e.run(ctx, cr, opentofu.InitApply, opts...)
 	if err != nil {
 		return fmt.Errorf("failed to apply: %w", err)
 	}
 
+	if op != nil {
+		if err := e.clearOperation(ctx, cr); err != nil {
+			return err
+		}
+		e.recorder.Eventf(cr, corev1.EventTypeNormal, reasonOperationStarted,
+			"opentofu apply %s started", op)
+	}
+
 	e.recorder.Eventf(cr, corev1.EventTypeNormal, reasonUpdated,
 		"opentofu apply '%s (id: %s)' success", cr.GetName(), cr.GetUID())
 